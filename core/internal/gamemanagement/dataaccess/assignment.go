@@ -0,0 +1,14 @@
+package dataaccess
+
+import "gorm.io/gorm"
+
+// Assignment is a drawn lot, the giver has to gift the receiver. With several gifts per player
+// every giver has as many assignments as the game has gifts per player.
+type Assignment struct {
+	gorm.Model
+	GameID     uint
+	GiverID    uint
+	Giver      Player `gorm:"foreignKey:GiverID"`
+	ReceiverID uint
+	Receiver   Player `gorm:"foreignKey:ReceiverID"`
+}
@@ -0,0 +1,72 @@
+package dataaccess
+
+import (
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+	"gorm.io/gorm/clause"
+)
+
+// AssignmentRepository holds all the database access functions
+type AssignmentRepository interface {
+	CreateAssignment(c dataaccess.Connection, assignment *Assignment)
+	DeleteAssignmentsByGameID(c dataaccess.Connection, gameID uint)
+	DeleteAssignmentsByPlayerID(c dataaccess.Connection, playerID uint)
+	DeleteAssignmentsByGiverID(c dataaccess.Connection, giverID uint)
+	FindAssignmentsByGameID(gameID uint) ([]*Assignment, error)
+	FindAssignmentsWithAssociationsByGiverID(giverID uint) ([]*Assignment, error)
+}
+
+type assignmentRepository struct {
+	connection dataaccess.Connection
+}
+
+// NewAssignmentRepository is the factory method for creating an assignment repository
+func NewAssignmentRepository(connection dataaccess.Connection) AssignmentRepository {
+
+	return &assignmentRepository{connection: connection}
+}
+
+// CreateAssignment creates an assignment
+func (assignmentRepository *assignmentRepository) CreateAssignment(c dataaccess.Connection, assignment *Assignment) {
+
+	c.Connection().Create(assignment)
+}
+
+// DeleteAssignmentsByGameID deletes all assignments of a game
+func (assignmentRepository *assignmentRepository) DeleteAssignmentsByGameID(c dataaccess.Connection, gameID uint) {
+
+	c.Connection().Where("game_id = ?", gameID).Delete(&Assignment{})
+}
+
+// DeleteAssignmentsByPlayerID deletes all assignments a player gifts or is gifted in
+func (assignmentRepository *assignmentRepository) DeleteAssignmentsByPlayerID(c dataaccess.Connection, playerID uint) {
+
+	c.Connection().Delete(&Assignment{}, "giver_id = ? OR receiver_id = ?", playerID, playerID)
+}
+
+// DeleteAssignmentsByGiverID deletes all assignments of a giver
+func (assignmentRepository *assignmentRepository) DeleteAssignmentsByGiverID(c dataaccess.Connection, giverID uint) {
+
+	c.Connection().Where("giver_id = ?", giverID).Delete(&Assignment{})
+}
+
+// FindAssignmentsByGameID Get all Assignments by Game ID
+func (assignmentRepository *assignmentRepository) FindAssignmentsByGameID(gameID uint) ([]*Assignment, error) {
+
+	var assignments []*Assignment
+	result := assignmentRepository.connection.Connection().Where("game_id = ?", gameID).Order("id").Find(&assignments)
+	if result.Error != nil {
+		return make([]*Assignment, 0), result.Error
+	}
+	return assignments, nil
+}
+
+// FindAssignmentsWithAssociationsByGiverID Get all Assignments of a giver including Associations
+func (assignmentRepository *assignmentRepository) FindAssignmentsWithAssociationsByGiverID(giverID uint) ([]*Assignment, error) {
+
+	var assignments []*Assignment
+	result := assignmentRepository.connection.Connection().Preload(clause.Associations).Where("giver_id = ?", giverID).Order("id").Find(&assignments)
+	if result.Error != nil {
+		return make([]*Assignment, 0), result.Error
+	}
+	return assignments, nil
+}
@@ -1,6 +1,9 @@
 package dataaccess
 
-import "gorm.io/gorm"
+import (
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
 
 // MigrateDb migrates the DB to the current schema version
 func MigrateDb(database *gorm.DB) {
@@ -8,4 +11,70 @@ func MigrateDb(database *gorm.DB) {
 	database.AutoMigrate(&Game{})
 	database.AutoMigrate(&Player{})
 	database.AutoMigrate(&PlayerException{})
+	database.AutoMigrate(&Household{})
+	database.AutoMigrate(&Assignment{})
+	database.AutoMigrate(&PlayerPreference{})
+	database.AutoMigrate(&DrawAudit{})
+	database.AutoMigrate(&DrawRound{})
+	database.AutoMigrate(&RoundAssignment{})
+	database.AutoMigrate(&Invite{})
+	database.AutoMigrate(&LoginAttempt{})
+	database.AutoMigrate(&LoginAudit{})
+	migrateGiftedToAssignments(database)
+	migratePlayerNames(database)
+}
+
+// migrateGiftedToAssignments moves the lots drawn before a player could gift several players into the assignments.
+// The column is only dropped in the same transaction after the lots are copied, otherwise it is tried again on the next start.
+func migrateGiftedToAssignments(database *gorm.DB) {
+
+	if !database.Migrator().HasColumn(&Player{}, "gifted_id") {
+		return
+	}
+	err := database.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("INSERT INTO assignments (created_at, updated_at, game_id, giver_id, receiver_id) SELECT updated_at, updated_at, game_id, id, gifted_id FROM players WHERE gifted_id IS NOT NULL AND deleted_at IS NULL").Error
+		if err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&Player{}, "gifted_id")
+	})
+	if err != nil {
+		log.Errorln("Failed to move the lots into the assignments, the gifted column is kept", err)
+	}
+}
+
+// migratePlayerNames fills the name key and display name of the players created before they existed.
+// Names which only differed in case or spacing are the same name now, such players are reported and have to be
+// renamed by hand, the unique index on the name key is only created once there are none left.
+func migratePlayerNames(database *gorm.DB) {
+
+	var players []*Player
+	database.Where("name_key IS NULL OR name_key = ?", "").Find(&players)
+	for _, player := range players {
+		if err := database.Save(player).Error; err != nil {
+			log.Errorln("Failed to fill the name key of player", player.ID, err)
+		}
+	}
+	if database.Migrator().HasIndex(&Player{}, "idx_players_game_name_key") {
+		return
+	}
+	var duplicates []struct {
+		GameID  uint
+		NameKey string
+		Count   int
+	}
+	err := database.Model(&Player{}).Select("game_id, name_key, count(*) AS count").Group("game_id, name_key").Having("count(*) > 1").Scan(&duplicates).Error
+	if err != nil {
+		log.Errorln("Failed to look for players with the same name", err)
+		return
+	}
+	for _, duplicate := range duplicates {
+		log.Errorf("%d players of game %d have the name %q, rename all but one of them", duplicate.Count, duplicate.GameID, duplicate.NameKey)
+	}
+	if len(duplicates) > 0 {
+		return
+	}
+	if err := database.Migrator().CreateIndex(&Player{}, "idx_players_game_name_key"); err != nil {
+		log.Errorln("Failed to create the unique index on the player names", err)
+	}
 }
@@ -0,0 +1,19 @@
+package dataaccess
+
+import "gorm.io/gorm"
+
+// DrawAudit records how the lots of a game were drawn, so that the draw can be verified later.
+// The Commitment is published at draw time, the Seed is kept secret until the gifts are exchanged.
+type DrawAudit struct {
+	gorm.Model
+	GameID           uint
+	AlgorithmVersion string
+	Seed             int64
+	Commitment       string
+	// Input is the JSON encoded input of the draw
+	Input string `gorm:"type:text"`
+	// Result is the JSON encoded lots of the draw, the players are the indices in the input
+	Result string `gorm:"type:text"`
+	// Repairs is the JSON encoded list of the repairs of the lots after the draw
+	Repairs string `gorm:"type:text"`
+}
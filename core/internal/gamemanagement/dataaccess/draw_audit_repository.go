@@ -0,0 +1,58 @@
+package dataaccess
+
+import (
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+	"gorm.io/gorm"
+)
+
+// DrawAuditRepository holds all the database access functions
+type DrawAuditRepository interface {
+	CreateDrawAudit(c dataaccess.Connection, drawAudit *DrawAudit)
+	UpdateDrawAudit(c dataaccess.Connection, drawAudit *DrawAudit)
+	FindLatestDrawAuditByGameID(gameID uint) (DrawAudit, error)
+	FindDrawAuditByID(drawAuditID uint) (DrawAudit, error)
+}
+
+type drawAuditRepository struct {
+	connection dataaccess.Connection
+}
+
+// NewDrawAuditRepository is the factory method for creating a draw audit repository
+func NewDrawAuditRepository(connection dataaccess.Connection) DrawAuditRepository {
+
+	return &drawAuditRepository{connection: connection}
+}
+
+// CreateDrawAudit creates a draw audit
+func (drawAuditRepository *drawAuditRepository) CreateDrawAudit(c dataaccess.Connection, drawAudit *DrawAudit) {
+
+	c.Connection().Create(drawAudit)
+}
+
+// UpdateDrawAudit updates a draw audit
+func (drawAuditRepository *drawAuditRepository) UpdateDrawAudit(c dataaccess.Connection, drawAudit *DrawAudit) {
+
+	c.Connection().Save(drawAudit)
+}
+
+// FindLatestDrawAuditByGameID receives the audit of the last draw of a game
+func (drawAuditRepository *drawAuditRepository) FindLatestDrawAuditByGameID(gameID uint) (DrawAudit, error) {
+
+	var drawAudit DrawAudit
+	result := drawAuditRepository.connection.Connection().Where("game_id = ?", gameID).Order("id desc").Limit(1).Find(&drawAudit)
+	if result.RowsAffected == 0 {
+		return drawAudit, gorm.ErrRecordNotFound
+	}
+	return drawAudit, result.Error
+}
+
+// FindDrawAuditByID receives a draw audit by its ID
+func (drawAuditRepository *drawAuditRepository) FindDrawAuditByID(drawAuditID uint) (DrawAudit, error) {
+
+	var drawAudit DrawAudit
+	result := drawAuditRepository.connection.Connection().Where("id = ?", drawAuditID).Limit(1).Find(&drawAudit)
+	if result.RowsAffected == 0 {
+		return drawAudit, gorm.ErrRecordNotFound
+	}
+	return drawAudit, result.Error
+}
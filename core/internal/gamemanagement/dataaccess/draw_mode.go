@@ -0,0 +1,15 @@
+package dataaccess
+
+// DrawMode is the kind of assignment which is drawn for a game
+type DrawMode int
+
+const (
+	// DrawModeFree allows every assignment, the gifts may pass along several separate circles
+	DrawModeFree DrawMode = iota
+	// DrawModeSingleCycle passes all gifts along one closed circle (A→B→C→…→A)
+	DrawModeSingleCycle
+)
+
+func (drawMode DrawMode) String() string {
+	return [...]string{"Free", "SingleCycle"}[drawMode]
+}
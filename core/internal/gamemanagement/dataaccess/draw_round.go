@@ -0,0 +1,21 @@
+package dataaccess
+
+import "gorm.io/gorm"
+
+// DrawRound is an archived draw of a game which has been reset, the rounds of a game are numbered from 1.
+// DrawAuditID refers to the audit of the archived draw, if there is one.
+type DrawRound struct {
+	gorm.Model
+	GameID      uint
+	Number      int
+	DrawAuditID *uint
+	Assignments []RoundAssignment
+}
+
+// RoundAssignment is an archived lot of a draw round
+type RoundAssignment struct {
+	gorm.Model
+	DrawRoundID uint
+	GiverID     uint
+	ReceiverID  uint
+}
@@ -0,0 +1,47 @@
+package dataaccess
+
+import (
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+	"gorm.io/gorm"
+)
+
+// DrawRoundRepository holds all the database access functions
+type DrawRoundRepository interface {
+	CreateDrawRound(c dataaccess.Connection, drawRound *DrawRound)
+	DeleteDrawRoundByID(c dataaccess.Connection, drawRoundID uint)
+	FindLatestDrawRoundByGameID(gameID uint) (DrawRound, error)
+}
+
+type drawRoundRepository struct {
+	connection dataaccess.Connection
+}
+
+// NewDrawRoundRepository is the factory method for creating a draw round repository
+func NewDrawRoundRepository(connection dataaccess.Connection) DrawRoundRepository {
+
+	return &drawRoundRepository{connection: connection}
+}
+
+// CreateDrawRound creates a draw round together with its assignments
+func (drawRoundRepository *drawRoundRepository) CreateDrawRound(c dataaccess.Connection, drawRound *DrawRound) {
+
+	c.Connection().Create(drawRound)
+}
+
+// DeleteDrawRoundByID deletes a draw round and its assignments
+func (drawRoundRepository *drawRoundRepository) DeleteDrawRoundByID(c dataaccess.Connection, drawRoundID uint) {
+
+	c.Connection().Where("draw_round_id = ?", drawRoundID).Delete(&RoundAssignment{})
+	c.Connection().Delete(&DrawRound{}, drawRoundID)
+}
+
+// FindLatestDrawRoundByGameID receives the draw round of a game with the highest number including its assignments
+func (drawRoundRepository *drawRoundRepository) FindLatestDrawRoundByGameID(gameID uint) (DrawRound, error) {
+
+	var drawRound DrawRound
+	result := drawRoundRepository.connection.Connection().Preload("Assignments").Where("game_id = ?", gameID).Order("number desc").Limit(1).Find(&drawRound)
+	if result.RowsAffected == 0 {
+		return drawRound, gorm.ErrRecordNotFound
+	}
+	return drawRound, result.Error
+}
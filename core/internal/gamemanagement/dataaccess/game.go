@@ -1,13 +1,31 @@
 package dataaccess
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Game is the main game
 type Game struct {
 	gorm.Model
 	//ID    uint   `json:"id" gorm:"primary_key"`
-	Title       string `json:"title"`
-	Description string
-	Code        string `json:"author"`
-	Status      string `json:"status"`
+	Title             string `json:"title"`
+	Description       string
+	Code              string `json:"author"`
+	Status            string `json:"status"`
+	DrawMode          string `json:"drawMode"`
+	ForbidMutualPairs bool   `json:"forbidMutualPairs"`
+	// PreviousGameID links the game to the game of the year before, e.g. for a family playing every December
+	PreviousGameID *uint `json:"previousGameId"`
+	// AvoidRepeatYears is the number of previous games whose pairings should not be drawn again
+	AvoidRepeatYears int `json:"avoidRepeatYears"`
+	// GiftsPerPlayer is how many players every player gifts and is gifted by, 0 counts as 1 for games created before
+	GiftsPerPlayer int `json:"giftsPerPlayer"`
+	// EventDate is when the gifts are exchanged, afterwards the seed of the draw is revealed
+	EventDate *time.Time `json:"eventDate"`
+	// DrawStrategy is the name of the draw strategy, empty for the default strategy
+	DrawStrategy string `json:"drawStrategy"`
+	// Budget is what a gift should cost, as free text like "20 €"
+	Budget string `json:"budget"`
 }
@@ -10,6 +10,7 @@ type GameRepository interface {
 	CreateGame(c dataaccess.Connection, game *Game)
 	UpdateGame(c dataaccess.Connection, game *Game)
 	FindGameByCode(code string) (Game, error)
+	FindGameByID(id uint) (Game, error)
 }
 
 type gameRepository struct {
@@ -39,6 +40,17 @@ func (gameRepository *gameRepository) FindGameByCode(code string) (Game, error)
 	return game, result.Error
 }
 
+// FindGameByID receives a game by id
+func (gameRepository *gameRepository) FindGameByID(id uint) (Game, error) {
+
+	var game Game
+	result := gameRepository.connection.Connection().Where("id = ?", id).Limit(1).Find(&game)
+	if result.RowsAffected == 0 {
+		return game, gorm.ErrRecordNotFound
+	}
+	return game, result.Error
+}
+
 // UpdateGame updates a game
 func (gameRepository *gameRepository) UpdateGame(c dataaccess.Connection, game *Game) {
 
@@ -0,0 +1,10 @@
+package dataaccess
+
+import "gorm.io/gorm"
+
+// Household is a group of players, e.g. a family, whose members never gift each other
+type Household struct {
+	gorm.Model
+	Name   string `json:"name"`
+	GameID uint
+}
@@ -0,0 +1,59 @@
+package dataaccess
+
+import (
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+	"gorm.io/gorm"
+)
+
+// HouseholdRepository holds all the database access functions
+type HouseholdRepository interface {
+	CreateHousehold(c dataaccess.Connection, household *Household)
+	DeleteHouseholdByID(c dataaccess.Connection, householdID uint)
+	FindHouseholdByNameAndGameID(name string, gameID uint) (Household, error)
+	FindHouseholdsByGameID(gameID uint) ([]*Household, error)
+}
+
+type householdRepository struct {
+	connection dataaccess.Connection
+}
+
+// NewHouseholdRepository is the factory method for creating a household repository
+func NewHouseholdRepository(connection dataaccess.Connection) HouseholdRepository {
+
+	return &householdRepository{connection: connection}
+}
+
+// CreateHousehold creates a household
+func (householdRepository *householdRepository) CreateHousehold(c dataaccess.Connection, household *Household) {
+
+	c.Connection().Create(household)
+}
+
+// DeleteHouseholdByID deletes a household by its ID
+func (householdRepository *householdRepository) DeleteHouseholdByID(c dataaccess.Connection, householdID uint) {
+
+	var household Household
+	c.Connection().Delete(&household, householdID)
+}
+
+// FindHouseholdByNameAndGameID Get a Household by name and game id
+func (householdRepository *householdRepository) FindHouseholdByNameAndGameID(name string, gameID uint) (Household, error) {
+
+	var household Household
+	result := householdRepository.connection.Connection().Where("name = ? AND game_id = ?", name, gameID).Limit(1).Find(&household)
+	if result.RowsAffected == 0 {
+		return household, gorm.ErrRecordNotFound
+	}
+	return household, result.Error
+}
+
+// FindHouseholdsByGameID Get all Households by Game ID
+func (householdRepository *householdRepository) FindHouseholdsByGameID(gameID uint) ([]*Household, error) {
+
+	var households []*Household
+	result := householdRepository.connection.Connection().Where("game_id = ?", gameID).Find(&households)
+	if result.Error != nil {
+		return make([]*Household, 0), result.Error
+	}
+	return households, nil
+}
@@ -0,0 +1,17 @@
+package dataaccess
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Invite lets a player register once with the token of an invite link
+type Invite struct {
+	gorm.Model
+	GameID   uint
+	PlayerID uint
+	// TokenHash is the SHA-256 hash of the token, the token itself is only sent to the player
+	TokenHash string `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+}
@@ -0,0 +1,47 @@
+package dataaccess
+
+import (
+	"time"
+
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+)
+
+// InviteRepository holds all the database access functions
+type InviteRepository interface {
+	CreateInvite(c dataaccess.Connection, invite *Invite)
+	DeleteInvitesByPlayerID(c dataaccess.Connection, playerID uint)
+	UseInvite(c dataaccess.Connection, tokenHash string, playerID uint, now time.Time) (bool, error)
+}
+
+type inviteRepository struct {
+	connection dataaccess.Connection
+}
+
+// NewInviteRepository is the factory method for creating an invite repository
+func NewInviteRepository(connection dataaccess.Connection) InviteRepository {
+
+	return &inviteRepository{connection: connection}
+}
+
+// CreateInvite creates an invite
+func (inviteRepository *inviteRepository) CreateInvite(c dataaccess.Connection, invite *Invite) {
+
+	c.Connection().Create(invite)
+}
+
+// DeleteInvitesByPlayerID deletes all invites of a player. They are deleted for good, a revoked token must not be usable again.
+func (inviteRepository *inviteRepository) DeleteInvitesByPlayerID(c dataaccess.Connection, playerID uint) {
+
+	c.Connection().Unscoped().Delete(&Invite{}, "player_id = ?", playerID)
+}
+
+// UseInvite deletes the valid invite of a player with the hash of its token, so it can only be used once.
+// It returns whether such an invite existed. Invites which were only marked as deleted do not count.
+func (inviteRepository *inviteRepository) UseInvite(c dataaccess.Connection, tokenHash string, playerID uint, now time.Time) (bool, error) {
+
+	result := c.Connection().Unscoped().Where("token_hash = ? AND player_id = ? AND expires_at > ? AND deleted_at IS NULL", tokenHash, playerID, now).Delete(&Invite{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,14 @@
+package dataaccess
+
+import "time"
+
+// LoginAttempt counts the failed logins of a throttle key, e.g. of a client IP or of a player.
+// It has no soft delete, so that the key stays unique for the upsert.
+type LoginAttempt struct {
+	ID          uint   `gorm:"primarykey"`
+	ThrottleKey string `gorm:"uniqueIndex"`
+	Failures    int
+	LastFailure time.Time
+	// PreviousFailure is the failure before the last one, the wait of a new attempt is measured from it
+	PreviousFailure time.Time
+}
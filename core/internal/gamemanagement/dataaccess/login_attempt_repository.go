@@ -0,0 +1,49 @@
+package dataaccess
+
+import (
+	"time"
+
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+	"gorm.io/gorm"
+)
+
+// LoginAttemptRepository holds all the database access functions
+type LoginAttemptRepository interface {
+	AddLoginFailure(throttleKey string, at time.Time, since time.Time) (LoginAttempt, error)
+	RemoveLoginFailure(throttleKey string) error
+	DeleteLoginAttemptByKey(throttleKey string) error
+}
+
+type loginAttemptRepository struct {
+	connection dataaccess.Connection
+}
+
+// NewLoginAttemptRepository is the factory method for creating a login attempt repository
+func NewLoginAttemptRepository(connection dataaccess.Connection) LoginAttemptRepository {
+
+	return &loginAttemptRepository{connection: connection}
+}
+
+// AddLoginFailure counts a failed login of a throttle key in a single statement and returns the counted failures,
+// so that several instances and concurrent logins can count the same key. Failures before since are forgotten.
+func (loginAttemptRepository *loginAttemptRepository) AddLoginFailure(throttleKey string, at time.Time, since time.Time) (LoginAttempt, error) {
+
+	var loginAttempt LoginAttempt
+	result := loginAttemptRepository.connection.Connection().Raw("INSERT INTO login_attempts (throttle_key, failures, last_failure, previous_failure) VALUES (?, 1, ?, ?) "+
+		"ON CONFLICT (throttle_key) DO UPDATE SET failures = CASE WHEN login_attempts.last_failure < ? THEN 1 ELSE login_attempts.failures + 1 END, "+
+		"previous_failure = login_attempts.last_failure, last_failure = excluded.last_failure "+
+		"RETURNING id, throttle_key, failures, last_failure, previous_failure", throttleKey, at, at, since).Scan(&loginAttempt)
+	return loginAttempt, result.Error
+}
+
+// RemoveLoginFailure takes back one failed login of a throttle key
+func (loginAttemptRepository *loginAttemptRepository) RemoveLoginFailure(throttleKey string) error {
+
+	return loginAttemptRepository.connection.Connection().Model(&LoginAttempt{}).Where("throttle_key = ? AND failures > 0", throttleKey).Update("failures", gorm.Expr("failures - 1")).Error
+}
+
+// DeleteLoginAttemptByKey forgets the failed logins of a throttle key
+func (loginAttemptRepository *loginAttemptRepository) DeleteLoginAttemptByKey(throttleKey string) error {
+
+	return loginAttemptRepository.connection.Connection().Where("throttle_key = ?", throttleKey).Delete(&LoginAttempt{}).Error
+}
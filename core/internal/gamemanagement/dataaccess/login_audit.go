@@ -0,0 +1,13 @@
+package dataaccess
+
+import "gorm.io/gorm"
+
+// LoginAudit records a rejected login, so that attacks on the passwords can be traced
+type LoginAudit struct {
+	gorm.Model
+	GameCode string `gorm:"index"`
+	Name     string
+	ClientIP string
+	// Reason is why the login was rejected, e.g. "wrongPassword" or "locked"
+	Reason string
+}
@@ -0,0 +1,26 @@
+package dataaccess
+
+import (
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+)
+
+// LoginAuditRepository holds all the database access functions
+type LoginAuditRepository interface {
+	CreateLoginAudit(c dataaccess.Connection, loginAudit *LoginAudit) error
+}
+
+type loginAuditRepository struct {
+	connection dataaccess.Connection
+}
+
+// NewLoginAuditRepository is the factory method for creating a login audit repository
+func NewLoginAuditRepository(connection dataaccess.Connection) LoginAuditRepository {
+
+	return &loginAuditRepository{connection: connection}
+}
+
+// CreateLoginAudit records a rejected login
+func (loginAuditRepository *loginAuditRepository) CreateLoginAudit(c dataaccess.Connection, loginAudit *LoginAudit) error {
+
+	return c.Connection().Create(loginAudit).Error
+}
@@ -1,15 +1,43 @@
 package dataaccess
 
-import "gorm.io/gorm"
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
 
 // Player is a player in the game
 type Player struct {
 	gorm.Model
-	Name     string `json:"name"`
-	Password string `json:"password"`
-	GameID   uint
-	Status   string
-	Role     string
-	GiftedID *uint
-	Gifted   *Player `gorm:"foreignKey:GiftedID"`
+	// Name is the login of the player and never changes
+	Name string `json:"name"`
+	// DisplayName is the name shown to the other players, it starts as the login name
+	DisplayName string `json:"displayName"`
+	// NameKey is the normalized login name, players are looked up by it and it is unique in a game
+	NameKey     string `json:"-" gorm:"uniqueIndex:idx_players_game_name_key,where:deleted_at IS NULL"`
+	Password    string `json:"password"`
+	GameID      uint   `gorm:"uniqueIndex:idx_players_game_name_key,where:deleted_at IS NULL"`
+	Status      string
+	Role        string
+	HouseholdID *uint
+	// ReceiversChanged is set when a repair of the lots gave the player other receivers, only the player is shown it
+	ReceiversChanged bool `json:"-"`
+}
+
+// BeforeSave keeps the name key in sync with the login name and shows the login name until the player is renamed
+func (player *Player) BeforeSave(tx *gorm.DB) error {
+
+	player.NameKey = NormalizeName(player.Name)
+	if player.DisplayName == "" {
+		player.DisplayName = player.Name
+	}
+	return nil
+}
+
+// NormalizeName makes names comparable regardless of case and Unicode composition, "Jürgen" and "jürgen" are the same name
+func NormalizeName(name string) string {
+
+	return norm.NFC.String(cases.Fold().String(norm.NFC.String(strings.TrimSpace(name))))
 }
@@ -10,6 +10,8 @@ import (
 type PlayerExceptionRepository interface {
 	CreatePlayerException(c dataaccess.Connection, playerException *PlayerException)
 	DeleteExceptionByPlayerID(c dataaccess.Connection, playerID uint)
+	DeleteExceptionByID(c dataaccess.Connection, exceptionID uint)
+	DeleteExceptionsByGameID(c dataaccess.Connection, gameID uint)
 	FindExceptionByIds(playerAId uint, playerBId uint, gameID uint) (PlayerException, error)
 	FindExceptionsWithAssociationsByGameID(gameID uint) ([]*PlayerException, error)
 }
@@ -55,3 +57,17 @@ func (playerExceptionRepository *playerExceptionRepository) DeleteExceptionByPla
 	var exception PlayerException
 	c.Connection().Delete(&exception, "player_a_id = ? OR player_b_id = ?", playerID, playerID)
 }
+
+// DeleteExceptionByID deletes an Exception by its ID
+func (playerExceptionRepository *playerExceptionRepository) DeleteExceptionByID(c dataaccess.Connection, exceptionID uint) {
+
+	var exception PlayerException
+	c.Connection().Delete(&exception, exceptionID)
+}
+
+// DeleteExceptionsByGameID deletes all Exceptions of a game
+func (playerExceptionRepository *playerExceptionRepository) DeleteExceptionsByGameID(c dataaccess.Connection, gameID uint) {
+
+	var exception PlayerException
+	c.Connection().Delete(&exception, "game_id = ?", gameID)
+}
@@ -0,0 +1,15 @@
+package dataaccess
+
+import "gorm.io/gorm"
+
+// PlayerPreference is a soft wish that PlayerA should rather not gift PlayerB, e.g. because they work in the same department.
+// Unlike a PlayerException it may be broken, every broken preference adds its Weight to the penalty of the draw.
+type PlayerPreference struct {
+	gorm.Model
+	PlayerAID uint
+	PlayerBID uint
+	PlayerA   Player `gorm:"foreignKey:PlayerAID"`
+	PlayerB   Player `gorm:"foreignKey:PlayerBID"`
+	Weight    int
+	GameID    uint
+}
@@ -0,0 +1,72 @@
+package dataaccess
+
+import (
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PlayerPreferenceRepository holds all the database access functions
+type PlayerPreferenceRepository interface {
+	CreatePlayerPreference(c dataaccess.Connection, playerPreference *PlayerPreference)
+	UpdatePlayerPreference(c dataaccess.Connection, playerPreference *PlayerPreference)
+	DeletePreferenceByID(c dataaccess.Connection, preferenceID uint)
+	DeletePreferenceByPlayerID(c dataaccess.Connection, playerID uint)
+	FindPreferenceByIds(playerAId uint, playerBId uint, gameID uint) (PlayerPreference, error)
+	FindPreferencesWithAssociationsByGameID(gameID uint) ([]*PlayerPreference, error)
+}
+
+type playerPreferenceRepository struct {
+	connection dataaccess.Connection
+}
+
+// NewPlayerPreferenceRepository is the factory method for creating a PlayerPreference repository
+func NewPlayerPreferenceRepository(connection dataaccess.Connection) PlayerPreferenceRepository {
+
+	return &playerPreferenceRepository{connection: connection}
+}
+
+// CreatePlayerPreference creates a Preference
+func (playerPreferenceRepository *playerPreferenceRepository) CreatePlayerPreference(c dataaccess.Connection, playerPreference *PlayerPreference) {
+
+	c.Connection().Create(playerPreference)
+}
+
+// UpdatePlayerPreference updates a Preference
+func (playerPreferenceRepository *playerPreferenceRepository) UpdatePlayerPreference(c dataaccess.Connection, playerPreference *PlayerPreference) {
+
+	c.Connection().Save(playerPreference)
+}
+
+// DeletePreferenceByID deletes a Preference by its ID
+func (playerPreferenceRepository *playerPreferenceRepository) DeletePreferenceByID(c dataaccess.Connection, preferenceID uint) {
+
+	var preference PlayerPreference
+	c.Connection().Delete(&preference, preferenceID)
+}
+
+// DeletePreferenceByPlayerID deletes all Preferences of a player
+func (playerPreferenceRepository *playerPreferenceRepository) DeletePreferenceByPlayerID(c dataaccess.Connection, playerID uint) {
+
+	var preference PlayerPreference
+	c.Connection().Delete(&preference, "player_a_id = ? OR player_b_id = ?", playerID, playerID)
+}
+
+// FindPreferenceByIds receives a preference by player ids and game id
+func (playerPreferenceRepository *playerPreferenceRepository) FindPreferenceByIds(playerAId uint, playerBId uint, gameID uint) (PlayerPreference, error) {
+
+	var existingPreference PlayerPreference
+	result := playerPreferenceRepository.connection.Connection().Where("player_a_id = ? AND player_b_id = ? AND game_id = ?", playerAId, playerBId, gameID).Limit(1).Find(&existingPreference)
+	if result.RowsAffected == 0 {
+		return existingPreference, gorm.ErrRecordNotFound
+	}
+	return existingPreference, nil
+}
+
+// FindPreferencesWithAssociationsByGameID Get existing Preferences by Game ID including Associations
+func (playerPreferenceRepository *playerPreferenceRepository) FindPreferencesWithAssociationsByGameID(gameID uint) ([]*PlayerPreference, error) {
+
+	var playerPreferences []*PlayerPreference
+	result := playerPreferenceRepository.connection.Connection().Where("game_id = ?", gameID).Preload(clause.Associations).Find(&playerPreferences)
+	return playerPreferences, result.Error
+}
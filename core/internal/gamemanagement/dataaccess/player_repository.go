@@ -11,10 +11,14 @@ type PlayerRepository interface {
 	CreatePlayer(c dataaccess.Connection, player *Player)
 	UpdatePlayer(c dataaccess.Connection, player *Player)
 	DeletePlayerByNameAndGameID(c dataaccess.Connection, playerName string, gameID uint)
+	ClearHouseholdByID(c dataaccess.Connection, householdID uint)
+	MarkReceiversChangedByID(c dataaccess.Connection, playerID uint)
+	ClearReceiversChangedByGameID(c dataaccess.Connection, gameID uint)
 	FindPlayerByNameAndGameID(name string, gameID uint) (Player, error)
 	FindPlayerWithAssociationsByNameAndGameID(playerName string, gameID uint) (Player, error)
 	FindFirstUnreadyPlayerByGameID(gameID uint) (Player, bool, error)
 	FindPlayersByGameID(gameID uint) ([]*Player, error)
+	LockManagersByGameID(c dataaccess.Connection, gameID uint) ([]*Player, error)
 }
 
 type playerRepository struct {
@@ -43,7 +47,10 @@ func (playerRepository *playerRepository) UpdatePlayer(c dataaccess.Connection,
 func (playerRepository *playerRepository) FindPlayerByNameAndGameID(name string, gameID uint) (Player, error) {
 
 	var player Player
-	result := playerRepository.connection.Connection().Where("name = ? AND game_id = ?", name, gameID).Limit(1).Find(&player)
+	result := playerRepository.connection.Connection().Where("name_key = ? AND game_id = ?", NormalizeName(name), gameID).Limit(1).Find(&player)
+	if result.Error != nil {
+		return player, result.Error
+	}
 	if result.RowsAffected == 0 {
 		return player, gorm.ErrRecordNotFound
 	}
@@ -54,7 +61,7 @@ func (playerRepository *playerRepository) FindPlayerByNameAndGameID(name string,
 func (playerRepository *playerRepository) FindPlayerWithAssociationsByNameAndGameID(playerName string, gameID uint) (Player, error) {
 
 	var player Player
-	result := playerRepository.connection.Connection().Preload(clause.Associations).Where("name = ? AND game_id = ?", playerName, gameID).Limit(1).Find(&player)
+	result := playerRepository.connection.Connection().Preload(clause.Associations).Where("name_key = ? AND game_id = ?", NormalizeName(playerName), gameID).Limit(1).Find(&player)
 	if result.RowsAffected == 0 {
 		return player, gorm.ErrRecordNotFound
 	}
@@ -72,6 +79,17 @@ func (playerRepository *playerRepository) FindPlayersByGameID(gameID uint) ([]*P
 	return players, nil
 }
 
+// LockManagersByGameID Get all admins and organizers of a game and lock them until the transaction ends
+func (playerRepository *playerRepository) LockManagersByGameID(c dataaccess.Connection, gameID uint) ([]*Player, error) {
+
+	var players []*Player
+	result := c.Connection().Clauses(clause.Locking{Strength: "UPDATE"}).Where("game_id = ? AND role IN ?", gameID, []string{RoleAdmin.String(), RoleOrganizer.String()}).Find(&players)
+	if result.Error != nil {
+		return make([]*Player, 0), result.Error
+	}
+	return players, nil
+}
+
 // FindFirstUnreadyPlayerByGameID Get the first unready Player for a GameId
 func (playerRepository *playerRepository) FindFirstUnreadyPlayerByGameID(gameID uint) (Player, bool, error) {
 
@@ -88,5 +106,23 @@ func (playerRepository *playerRepository) FindFirstUnreadyPlayerByGameID(gameID
 func (playerRepository *playerRepository) DeletePlayerByNameAndGameID(c dataaccess.Connection, playerName string, gameID uint) {
 
 	var player Player
-	c.Connection().Delete(&player, "name = ? AND game_id = ?", playerName, gameID)
+	c.Connection().Delete(&player, "name_key = ? AND game_id = ?", NormalizeName(playerName), gameID)
+}
+
+// ClearHouseholdByID removes all players from a household
+func (playerRepository *playerRepository) ClearHouseholdByID(c dataaccess.Connection, householdID uint) {
+
+	c.Connection().Model(&Player{}).Where("household_id = ?", householdID).Update("household_id", nil)
+}
+
+// MarkReceiversChangedByID marks that the receivers of a player changed after the draw
+func (playerRepository *playerRepository) MarkReceiversChangedByID(c dataaccess.Connection, playerID uint) {
+
+	c.Connection().Model(&Player{}).Where("id = ?", playerID).Update("receivers_changed", true)
+}
+
+// ClearReceiversChangedByGameID removes the marks of changed receivers from all players of a game
+func (playerRepository *playerRepository) ClearReceiversChangedByGameID(c dataaccess.Connection, gameID uint) {
+
+	c.Connection().Model(&Player{}).Where("game_id = ? AND receivers_changed", gameID).Update("receivers_changed", false)
 }
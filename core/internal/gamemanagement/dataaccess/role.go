@@ -8,8 +8,20 @@ const (
 	RoleAdmin Role = iota
 	// RolePlayer is a gamer of the game
 	RolePlayer
+	// RoleOrganizer manages a game like an admin but does not take part in the draw
+	RoleOrganizer
 )
 
 func (role Role) String() string {
-	return [...]string{"Admin", "Player"}[role]
+	return [...]string{"Admin", "Player", "Organizer"}[role]
+}
+
+// CanManage tells whether a player of the role may manage the game
+func CanManage(role string) bool {
+	return role == RoleAdmin.String() || role == RoleOrganizer.String()
+}
+
+// TakesPart tells whether a player of the role takes part in the draw
+func TakesPart(role string) bool {
+	return role != RoleOrganizer.String()
 }
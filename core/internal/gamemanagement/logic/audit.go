@@ -0,0 +1,123 @@
+package logic
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/dataaccess"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+	glogic "github.com/yoktobit/secretsanta/internal/general/logic"
+	"gorm.io/gorm"
+)
+
+// drawAlgorithmVersion names the draw algorithm together with the generator of its seeded randomizer.
+// It has to change whenever the same seed and input would draw different lots.
+const drawAlgorithmVersion = "2"
+
+// drawRandomizers create the seeded randomizer of every algorithm version which can still be verified.
+// Version 1 drew with math/rand, which only uses about 31 bits of the seed, so everyone could try all seeds after the reveal.
+// Version 2 derives every number from the full seed.
+var drawRandomizers = map[string]func(seed int64) glogic.Randomizer{
+	"1":                  glogic.NewLegacySeededRandomizer,
+	drawAlgorithmVersion: glogic.NewSeededRandomizer,
+}
+
+// drawRepairDropOut and drawRepairLateJoin are the kinds of repairs recorded in the audit of a draw
+const (
+	drawRepairDropOut  = "dropOut"
+	drawRepairLateJoin = "lateJoin"
+)
+
+// runDraw draws the lots for an input. Pairings of previous years are avoided as long as possible,
+// if that fails the oldest year is allowed again. Besides the lots the penalty score
+// and the number of previous years which could be respected are returned.
+// All searches of the draw share one budget, once it is exhausted the draw gives up.
+func runDraw(input to.DrawInputTo, random glogic.Randomizer) DrawResult {
+
+	rules := drawRules{singleCycle: input.SingleCycle, forbidMutualPairs: input.ForbidMutualPairs}
+	budget := newSearchBudget()
+	years := len(input.History)
+	assignment, score, ok := drawPreferredAssignment(excludePairings(input.Allowed, input.History), input.Penalty, input.GiftsPerPlayer, rules, random, budget)
+	for !ok && years > 0 && !budget.exhausted {
+		years--
+		assignment, score, ok = drawPreferredAssignment(excludePairings(input.Allowed, input.History[:years]), input.Penalty, input.GiftsPerPlayer, rules, random, budget)
+	}
+	return DrawResult{Assignment: assignment, Score: score, Years: years, Ok: ok, GaveUp: !ok && budget.exhausted}
+}
+
+// drawCommitment is the hash published at draw time. It binds the algorithm version, and so the generator of the
+// randomizer, the seed and the input, so none of them can be changed afterwards without the commitment changing.
+func drawCommitment(algorithmVersion string, seed int64, input to.DrawInputTo) (string, error) {
+
+	encodedInput, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s\n%d\n%s", algorithmVersion, seed, encodedInput)))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// VerifyDraw checks a revealed draw of the built-in draw strategy offline, see DrawStrategies.Verify
+func VerifyDraw(audit to.DrawAuditTo) error {
+
+	return NewDefaultDrawStrategies().Verify(audit)
+}
+
+// decodeRepairs returns the repairs recorded in an audit
+func decodeRepairs(drawAudit *dataaccess.DrawAudit) ([]to.DrawRepairTo, error) {
+
+	repairs := make([]to.DrawRepairTo, 0)
+	if drawAudit.Repairs == "" {
+		return repairs, nil
+	}
+	err := json.Unmarshal([]byte(drawAudit.Repairs), &repairs)
+	return repairs, err
+}
+
+// currentDrawAudit returns the audit of the lots a game currently has. The latest audit of a reset game belongs
+// to an archived round, so a game only has a current audit while it is drawn.
+func (gamemanagement *gamemanagement) currentDrawAudit(game *dataaccess.Game) (dataaccess.DrawAudit, error) {
+
+	if GameStatus(game) != dataaccess.StatusDrawn {
+		return dataaccess.DrawAudit{}, gorm.ErrRecordNotFound
+	}
+	return gamemanagement.drawAuditRepository.FindLatestDrawAuditByGameID(game.ID)
+}
+
+// auditRepair adds a repair of the lots to the audit of the last draw, with all players and lots after the repair.
+// nil is returned for a game drawn before draws were audited.
+func (gamemanagement *gamemanagement) auditRepair(game *dataaccess.Game, kind string, name string, players []*dataaccess.Player, assignment [][]int) (*dataaccess.DrawAudit, error) {
+
+	drawAudit, err := gamemanagement.drawAuditRepository.FindLatestDrawAuditByGameID(game.ID)
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	repairs, err := decodeRepairs(&drawAudit)
+	if err != nil {
+		return nil, err
+	}
+	repair := to.DrawRepairTo{Kind: kind, Player: name, RepairedAt: time.Now(), Players: make([]string, len(players)), Result: copyAssignment(assignment)}
+	for i, player := range players {
+		repair.Players[i] = player.Name
+	}
+	encodedRepairs, err := json.Marshal(append(repairs, repair))
+	if err != nil {
+		return nil, err
+	}
+	drawAudit.Repairs = string(encodedRepairs)
+	return &drawAudit, nil
+}
+
+// revealsAudit tells whether the seed and the lots of a draw may be published. This is the case once the event
+// has taken place, as long as it took place after the draw. An event date which had already passed when the game
+// was drawn would reveal the lots to everyone right away.
+func revealsAudit(game *dataaccess.Game, drawAudit *dataaccess.DrawAudit, now time.Time) bool {
+
+	return game.EventDate != nil && game.EventDate.After(drawAudit.CreatedAt) && now.After(*game.EventDate)
+}
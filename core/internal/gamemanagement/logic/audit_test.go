@@ -0,0 +1,188 @@
+package logic_test
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/errors"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+	glogic "github.com/yoktobit/secretsanta/internal/general/logic"
+	"gorm.io/gorm"
+)
+
+// captureArgument matches every argument and remembers its value
+type captureArgument struct {
+	value *driver.Value
+}
+
+func (captureArgument captureArgument) Match(value driver.Value) bool {
+	*captureArgument.value = value
+	return true
+}
+
+var _ = Describe("DrawAudit", func() {
+
+	var gamemanagement logic.Gamemanagement
+	var mock sqlmock.Sqlmock
+	code := "ABC"
+
+	BeforeEach(func() {
+		var c dataaccess.Connection
+		c, mock = dataaccess.NewMockConnection()
+		gamemanagement = newTestGamemanagement(c, nil, nil)
+	})
+
+	playerRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready").AddRow(4, "Strolch", 1, "Ready")
+	}
+
+	// drawAndReveal draws a game, then reads its audit after the event date with the lots stored at draw time
+	drawAndReveal := func() to.DrawAuditTo {
+		givers := make([]driver.Value, 4)
+		receivers := make([]driver.Value, 4)
+		var commitment, input, result driver.Value
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(playerRows())
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(false, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		for i := range givers {
+			mock.ExpectQuery(`INSERT INTO "assignments"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, captureArgument{&givers[i]}, captureArgument{&receivers[i]}).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(i + 1))
+		}
+		mock.ExpectQuery(`INSERT INTO "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "2", 42, captureArgument{&commitment}, captureArgument{&input}, captureArgument{&result}, "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		drawGameResponseTo, err := gamemanagement.DrawGame(to.DrawGameTo{GameCode: code})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drawGameResponseTo.Ok).To(BeTrue())
+		Expect(drawGameResponseTo.Commitment).To(Equal(commitment))
+
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "event_date"}).AddRow(1, code, "Drawn", time.Now().Add(-time.Hour)))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "game_id", "algorithm_version", "seed", "commitment", "input", "result"}).AddRow(1, time.Now().Add(-2*time.Hour), 1, "2", 42, commitment, input, result))
+		drawAuditTo, err := gamemanagement.GetDrawAudit(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		return drawAuditTo
+	}
+
+	It("should reveal a draw which can be verified after the event", func() {
+		drawAuditTo := drawAndReveal()
+		Expect(drawAuditTo.Revealed).To(BeTrue())
+		Expect(drawAuditTo.Seed).To(Equal(int64(42)))
+		Expect(drawAuditTo.Input.Players).To(Equal([]string{"Max", "Moritz", "Susi", "Strolch"}))
+		Expect(drawAuditTo.Result).To(HaveLen(4))
+		Expect(logic.VerifyDraw(drawAuditTo)).To(Succeed())
+	})
+	It("should detect changed lots", func() {
+		drawAuditTo := drawAndReveal()
+		drawAuditTo.Result[0], drawAuditTo.Result[1] = drawAuditTo.Result[1], drawAuditTo.Result[0]
+		Expect(logic.VerifyDraw(drawAuditTo)).To(MatchError(errors.ErrDrawVerificationFailed))
+	})
+	It("should detect a changed seed", func() {
+		drawAuditTo := drawAndReveal()
+		drawAuditTo.Seed++
+		Expect(logic.VerifyDraw(drawAuditTo)).To(MatchError(errors.ErrDrawVerificationFailed))
+	})
+	It("should detect a changed input", func() {
+		drawAuditTo := drawAndReveal()
+		drawAuditTo.Input.Allowed[0][1] = false
+		Expect(logic.VerifyDraw(drawAuditTo)).To(MatchError(errors.ErrDrawVerificationFailed))
+	})
+	It("should bind the generator of the randomizer by the algorithm version", func() {
+		drawAuditTo := drawAndReveal()
+		drawAuditTo.AlgorithmVersion = "1"
+		Expect(logic.VerifyDraw(drawAuditTo)).To(MatchError(errors.ErrDrawVerificationFailed))
+	})
+	It("should draw with all bits of the seed", func() {
+		// math/rand reduces seeds modulo 2^31-1, so both seeds would draw the same numbers there
+		first := glogic.NewSeededRandomizer(1).Perm(20)
+		second := glogic.NewSeededRandomizer(1 + (1<<31 - 1)).Perm(20)
+		Expect(glogic.NewLegacySeededRandomizer(1).Perm(20)).To(Equal(glogic.NewLegacySeededRandomizer(1 + (1<<31 - 1)).Perm(20)))
+		Expect(first).ToNot(Equal(second))
+		Expect(glogic.NewSeededRandomizer(1).Perm(20)).To(Equal(first))
+	})
+	It("should refuse an unknown algorithm version", func() {
+		drawAuditTo := drawAndReveal()
+		drawAuditTo.AlgorithmVersion = "0"
+		Expect(logic.VerifyDraw(drawAuditTo)).To(MatchError(errors.ErrUnknownDrawAlgorithm))
+	})
+	It("should only publish the commitment before the event", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "event_date"}).AddRow(1, code, "Drawn", time.Now().Add(time.Hour)))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "algorithm_version", "seed", "commitment", "input"}).AddRow(1, 1, "1", 42, "abc", "{}"))
+		drawAuditTo, err := gamemanagement.GetDrawAudit(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drawAuditTo.Revealed).To(BeFalse())
+		Expect(drawAuditTo.Commitment).To(Equal("abc"))
+		Expect(drawAuditTo.Seed).To(BeZero())
+		Expect(drawAuditTo.Result).To(BeNil())
+		Expect(logic.VerifyDraw(drawAuditTo)).To(MatchError(errors.ErrSeedNotRevealed))
+	})
+	It("should not reveal a draw after an event date which had passed when the game was drawn", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "event_date"}).AddRow(1, code, "Drawn", time.Now().Add(-time.Hour)))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "game_id", "algorithm_version", "seed", "commitment", "input", "result"}).AddRow(1, time.Now().Add(-time.Minute), 1, "1", 42, "abc", "{}", "[[1],[0]]"))
+		drawAuditTo, err := gamemanagement.GetDrawAudit(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drawAuditTo.Revealed).To(BeFalse())
+		Expect(drawAuditTo.Seed).To(BeZero())
+		Expect(drawAuditTo.Result).To(BeNil())
+	})
+	It("should not draw a game whose event date has passed", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "event_date"}).AddRow(1, code, "Ready", time.Now().Add(-time.Hour)))
+		_, err := gamemanagement.DrawGame(to.DrawGameTo{GameCode: code})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(errors.ErrEventDatePassed))
+	})
+	It("should hide the repairs before the event", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "event_date"}).AddRow(1, code, "Drawn", time.Now().Add(time.Hour)))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "algorithm_version", "seed", "commitment", "input", "result", "repairs"}).AddRow(1, 1, "1", 42, "abc", "{}", "[[1],[0]]", `[{"kind":"dropOut","player":"Susi","repairedAt":"2021-12-01T00:00:00Z","players":["Max","Moritz"],"result":[[1],[0]]}]`))
+		drawAuditTo, err := gamemanagement.GetDrawAudit(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drawAuditTo.Repairs).To(HaveLen(1))
+		Expect(drawAuditTo.Repairs[0].Kind).To(Equal("dropOut"))
+		Expect(drawAuditTo.Repairs[0].Player).To(Equal("Susi"))
+		Expect(drawAuditTo.Repairs[0].Players).To(BeNil())
+		Expect(drawAuditTo.Repairs[0].Result).To(BeNil())
+	})
+	It("should reveal an audit without stored lots by the names of the players", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "event_date"}).AddRow(1, code, "Drawn", time.Now().Add(-time.Hour)))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "algorithm_version", "seed", "commitment", "input"}).AddRow(1, 1, "1", 42, "abc", `{"players":["Max","Moritz"]}`))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(7, "moritz ", 1).AddRow(9, "Max", 1))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "giver_id", "receiver_id"}).AddRow(1, 1, 9, 7).AddRow(2, 1, 7, 9))
+		drawAuditTo, err := gamemanagement.GetDrawAudit(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drawAuditTo.Result).To(Equal([][]int{{1}, {0}}))
+	})
+	It("should refuse an audit without stored lots if a player is unknown", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "event_date"}).AddRow(1, code, "Drawn", time.Now().Add(-time.Hour)))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "algorithm_version", "seed", "commitment", "input"}).AddRow(1, 1, "1", 42, "abc", `{"players":["Max","Moritz"]}`))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(7, "Moritz", 1).AddRow(8, "Strolch", 1).AddRow(9, "Max", 1))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "giver_id", "receiver_id"}).AddRow(1, 1, 9, 8).AddRow(2, 1, 8, 7).AddRow(3, 1, 7, 9))
+		_, err := gamemanagement.GetDrawAudit(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(errors.ErrDrawAuditOutdated))
+	})
+	It("should fail without a draw", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Drawn"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		_, err := gamemanagement.GetDrawAudit(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(gorm.ErrRecordNotFound))
+	})
+	It("should not show the audit of an archived round after a reset", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "event_date"}).AddRow(1, code, "Reset", time.Now().Add(-time.Hour)))
+		_, err := gamemanagement.GetDrawAudit(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(gorm.ErrRecordNotFound))
+	})
+})
@@ -0,0 +1,475 @@
+package logic
+
+import (
+	"math/bits"
+
+	glogic "github.com/yoktobit/secretsanta/internal/general/logic"
+)
+
+// maxExactDrawSize is the largest number of players for which valid assignments are counted exactly.
+// The counting needs 2^n memory cells, above this size the draw falls back to a random walk, see walkAssignment.
+// So the draw is only uniformly distributed up to this number of players.
+const maxExactDrawSize = 20
+
+// maxRejections is how often a drawn assignment may be rejected for breaking a rule before the draw is searched systematically
+const maxRejections = 1000
+
+// maxSearchSteps is how many elementary steps the backtracking searches of one draw may take together, which is well
+// below a second. With sparse exceptions a search may need exponentially many steps, and as the draw runs within
+// a request it gives up instead, see searchBudget.
+const maxSearchSteps = 50000000
+
+// searchBudget counts the steps left to the backtracking searches of one draw. A search which runs out of steps
+// returns false just like one which proved that there is no valid assignment, exhausted tells both apart.
+type searchBudget struct {
+	steps     int
+	exhausted bool
+}
+
+func newSearchBudget() *searchBudget {
+
+	return &searchBudget{steps: maxSearchSteps}
+}
+
+// spend takes the given number of steps from the budget, false is returned once there are not enough left
+func (budget *searchBudget) spend(steps int) bool {
+
+	if budget.steps < steps {
+		budget.exhausted = true
+		return false
+	}
+	budget.steps -= steps
+	return true
+}
+
+// drawRules are the rules of a draw which can not be expressed by single allowed pairs
+type drawRules struct {
+	singleCycle       bool
+	forbidMutualPairs bool
+}
+
+// drawAssignment draws a valid assignment which follows the rules.
+// Assignments with mutual pairs are rejected and drawn again, which keeps the distribution uniform up to maxExactDrawSize players.
+// If that fails too often, a systematic search decides whether a valid assignment exists at all,
+// unless it runs out of budget.
+func drawAssignment(allowed [][]bool, rules drawRules, random glogic.Randomizer, budget *searchBudget) ([]int, bool) {
+
+	if rules.singleCycle {
+		// a circle of three or more players never contains a mutual pair
+		if rules.forbidMutualPairs && len(allowed) == 2 {
+			return nil, false
+		}
+		return sampleCycle(allowed, random, budget)
+	}
+	if !rules.forbidMutualPairs {
+		return sampleAssignment(allowed, random)
+	}
+	if len(allowed) <= maxExactDrawSize {
+		counter := newAssignmentCounter(allowed)
+		if counter.count(0) == 0 {
+			return nil, false
+		}
+		for try := 0; try < maxRejections; try++ {
+			assignment := counter.sample(random)
+			if !hasMutualPair(assignment) {
+				return assignment, true
+			}
+		}
+	}
+	return searchWithoutMutualPairs(allowed, random, budget)
+}
+
+// sampleAssignment draws one of all valid assignments with equal probability for up to maxExactDrawSize players.
+// Larger draws are not uniform, see walkAssignment.
+func sampleAssignment(allowed [][]bool, random glogic.Randomizer) ([]int, bool) {
+
+	if len(allowed) > maxExactDrawSize {
+		return walkAssignment(allowed, random)
+	}
+	counter := newAssignmentCounter(allowed)
+	if counter.count(0) == 0 {
+		return nil, false
+	}
+	return counter.sample(random), true
+}
+
+// hasMutualPair tells whether two players gift each other
+func hasMutualPair(assignment []int) bool {
+
+	for giver, receiver := range assignment {
+		if assignment[receiver] == giver {
+			return true
+		}
+	}
+	return false
+}
+
+// searchWithoutMutualPairs searches a valid assignment without mutual pairs by randomized backtracking.
+// Partial assignments which can not be completed by a matching are cut off early.
+// The search is exhaustive, so false is only returned if no such assignment exists at all or if the budget is exhausted.
+func searchWithoutMutualPairs(allowed [][]bool, random glogic.Randomizer, budget *searchBudget) ([]int, bool) {
+
+	size := len(allowed)
+	assignment := make([]int, size)
+	for giver := range assignment {
+		assignment[giver] = -1
+	}
+	used := make([]bool, size)
+	var extend func(giver int) bool
+	extend = func(giver int) bool {
+		if giver == size {
+			return true
+		}
+		for _, receiver := range random.Perm(size) {
+			if used[receiver] || !allowed[giver][receiver] || assignment[receiver] == giver {
+				continue
+			}
+			assignment[giver] = receiver
+			used[receiver] = true
+			// checking whether the rest can be completed matches the remaining givers and receivers
+			if !budget.spend(size * size) {
+				return false
+			}
+			if canComplete(allowed, assignment, used, giver+1) && extend(giver+1) {
+				return true
+			}
+			assignment[giver] = -1
+			used[receiver] = false
+			if budget.exhausted {
+				return false
+			}
+		}
+		return false
+	}
+	if !extend(0) {
+		return nil, false
+	}
+	return assignment, true
+}
+
+// canComplete tells whether the givers from next on can still be matched to the unused receivers
+func canComplete(allowed [][]bool, assignment []int, used []bool, next int) bool {
+
+	receivers := make([]int, 0, len(used))
+	for receiver, isUsed := range used {
+		if !isUsed {
+			receivers = append(receivers, receiver)
+		}
+	}
+	remaining := make([][]bool, 0, len(receivers))
+	for giver := next; giver < len(allowed); giver++ {
+		row := make([]bool, len(receivers))
+		for column, receiver := range receivers {
+			row[column] = allowed[giver][receiver] && assignment[receiver] != giver
+		}
+		remaining = append(remaining, row)
+	}
+	return hasAssignment(remaining)
+}
+
+// walkAssignment starts with any valid assignment and swaps receivers of random giver pairs for a fixed number of steps.
+// The result is not uniformly distributed: it depends on the assignment the walk starts with, the number of steps
+// gives no bound on how close it gets to uniform, and with exceptions swaps of two givers may not reach every valid assignment.
+func walkAssignment(allowed [][]bool, random glogic.Randomizer) ([]int, bool) {
+
+	assignment, ok := findAssignment(allowed, random)
+	if !ok {
+		return nil, false
+	}
+	size := len(assignment)
+	for step := 0; step < 10*size*size; step++ {
+		a, b := random.NextInt(size), random.NextInt(size)
+		if a != b && allowed[a][assignment[b]] && allowed[b][assignment[a]] {
+			assignment[a], assignment[b] = assignment[b], assignment[a]
+		}
+	}
+	return assignment, true
+}
+
+// assignmentCounter counts the valid completions of partial assignments.
+// A partial assignment is described by the set of used receivers, the givers are assigned in order.
+type assignmentCounter struct {
+	allowed [][]bool
+	memo    []int
+}
+
+func newAssignmentCounter(allowed [][]bool) *assignmentCounter {
+
+	memo := make([]int, 1<<len(allowed))
+	for i := range memo {
+		memo[i] = -1
+	}
+	return &assignmentCounter{allowed: allowed, memo: memo}
+}
+
+// sample picks an assignment with equal probability, every giver picks a receiver
+// weighted by the number of assignments which can still be completed with it
+func (counter *assignmentCounter) sample(random glogic.Randomizer) []int {
+
+	size := len(counter.allowed)
+	assignment := make([]int, size)
+	mask := 0
+	for giver := 0; giver < size; giver++ {
+		pick := random.NextInt(counter.count(mask))
+		for receiver := 0; receiver < size; receiver++ {
+			if mask&(1<<receiver) != 0 || !counter.allowed[giver][receiver] {
+				continue
+			}
+			ways := counter.count(mask | 1<<receiver)
+			if pick < ways {
+				assignment[giver] = receiver
+				mask |= 1 << receiver
+				break
+			}
+			pick -= ways
+		}
+	}
+	return assignment
+}
+
+// count returns the number of valid assignments for the remaining givers if the receivers in mask are taken
+func (counter *assignmentCounter) count(mask int) int {
+
+	if counter.memo[mask] >= 0 {
+		return counter.memo[mask]
+	}
+	giver := bits.OnesCount(uint(mask))
+	if giver == len(counter.allowed) {
+		counter.memo[mask] = 1
+		return 1
+	}
+	ways := 0
+	for receiver, ok := range counter.allowed[giver] {
+		if ok && mask&(1<<receiver) == 0 {
+			ways += counter.count(mask | 1<<receiver)
+		}
+	}
+	counter.memo[mask] = ways
+	return ways
+}
+
+// maxExactCycleSize is the largest number of players for which single cycles are counted exactly.
+// The counting needs n*2^n memory cells, above this size the cycle is searched by backtracking.
+const maxExactCycleSize = 16
+
+// sampleCycle draws one of all valid assignments which form a single closed circle with equal probability.
+// If no such circle exists, false is returned. Above maxExactCycleSize players the circle is searched, see searchCycle.
+func sampleCycle(allowed [][]bool, random glogic.Randomizer, budget *searchBudget) ([]int, bool) {
+
+	size := len(allowed)
+	if size > maxExactCycleSize {
+		return searchCycle(allowed, random, budget)
+	}
+	if size == 0 {
+		return []int{}, true
+	}
+	counter := newCycleCounter(allowed)
+	if counter.count(1, 0) == 0 {
+		return nil, false
+	}
+	assignment := make([]int, size)
+	mask, current := 1, 0
+	for step := 1; step < size; step++ {
+		pick := random.NextInt(counter.count(mask, current))
+		for next := 1; next < size; next++ {
+			if mask&(1<<next) != 0 || !allowed[current][next] {
+				continue
+			}
+			ways := counter.count(mask|1<<next, next)
+			if pick < ways {
+				assignment[current] = next
+				mask |= 1 << next
+				current = next
+				break
+			}
+			pick -= ways
+		}
+	}
+	assignment[current] = 0
+	return assignment, true
+}
+
+// searchCycle searches a single closed circle by randomized backtracking. Paths which can no longer be closed
+// are cut off, see canCloseCycle. The search is exhaustive, so false is only returned if no circle exists at all
+// or if the budget is exhausted.
+func searchCycle(allowed [][]bool, random glogic.Randomizer, budget *searchBudget) ([]int, bool) {
+
+	size := len(allowed)
+	assignment := make([]int, size)
+	visited := make([]bool, size)
+	visited[0] = true
+	var extend func(current int, length int) bool
+	extend = func(current int, length int) bool {
+		if length == size {
+			if allowed[current][0] {
+				assignment[current] = 0
+				return true
+			}
+			return false
+		}
+		// checking whether the path can be closed looks at every pair of players
+		if !budget.spend(size*size) || !canCloseCycle(allowed, visited, current) {
+			return false
+		}
+		for _, next := range random.Perm(size) {
+			if visited[next] || !allowed[current][next] {
+				continue
+			}
+			visited[next] = true
+			assignment[current] = next
+			if extend(next, length+1) {
+				return true
+			}
+			visited[next] = false
+			if budget.exhausted {
+				return false
+			}
+		}
+		return false
+	}
+	if !extend(0, 1) {
+		return nil, false
+	}
+	return assignment, true
+}
+
+// canCloseCycle tells whether a path starting at player 0 and ending at current might still be closed to a single circle.
+// Every player off the path needs an allowed receiver off the path or at its start, and an allowed giver off the path
+// or at its end, and the start needs an allowed giver off the path or at its end. This is only a necessary condition,
+// but it is cheap and cuts off most dead ends early.
+func canCloseCycle(allowed [][]bool, visited []bool, current int) bool {
+
+	size := len(allowed)
+	for player := 0; player < size; player++ {
+		if visited[player] && player != 0 {
+			continue
+		}
+		hasReceiver, hasGiver := player == 0, false
+		for other := 0; other < size && !(hasReceiver && hasGiver); other++ {
+			if other == player {
+				continue
+			}
+			if allowed[player][other] && (!visited[other] || other == 0) {
+				hasReceiver = true
+			}
+			if allowed[other][player] && (!visited[other] || other == current) {
+				hasGiver = true
+			}
+		}
+		if !hasReceiver || !hasGiver {
+			return false
+		}
+	}
+	return true
+}
+
+// cycleCounter counts the ways to complete a path starting at player 0 to a single closed circle.
+// A path is described by the set of visited players and the player at its end.
+type cycleCounter struct {
+	allowed [][]bool
+	memo    []int
+}
+
+func newCycleCounter(allowed [][]bool) *cycleCounter {
+
+	memo := make([]int, len(allowed)<<len(allowed))
+	for i := range memo {
+		memo[i] = -1
+	}
+	return &cycleCounter{allowed: allowed, memo: memo}
+}
+
+// count returns the number of circles which continue the path visiting mask and ending at current
+func (counter *cycleCounter) count(mask int, current int) int {
+
+	size := len(counter.allowed)
+	index := mask*size + current
+	if counter.memo[index] >= 0 {
+		return counter.memo[index]
+	}
+	ways := 0
+	if mask == 1<<size-1 {
+		if counter.allowed[current][0] {
+			ways = 1
+		}
+	} else {
+		for next := 1; next < size; next++ {
+			if mask&(1<<next) == 0 && counter.allowed[current][next] {
+				ways += counter.count(mask|1<<next, next)
+			}
+		}
+	}
+	counter.memo[index] = ways
+	return ways
+}
+
+// findAssignment searches a perfect matching between givers and receivers where allowed[giver][receiver] is true.
+// The result maps every giver index to a receiver index. If no such assignment exists, false is returned,
+// which is a proof that the constraints can not be fulfilled, not just bad luck.
+func findAssignment(allowed [][]bool, random glogic.Randomizer) ([]int, bool) {
+
+	size := len(allowed)
+	giverOfReceiver := make([]int, size)
+	for receiver := range giverOfReceiver {
+		giverOfReceiver[receiver] = -1
+	}
+	candidates := make([][]int, size)
+	for giver := 0; giver < size; giver++ {
+		for _, receiver := range random.Perm(size) {
+			if allowed[giver][receiver] {
+				candidates[giver] = append(candidates[giver], receiver)
+			}
+		}
+	}
+	for _, giver := range random.Perm(size) {
+		visited := make([]bool, size)
+		if !augment(giver, candidates, giverOfReceiver, visited) {
+			return nil, false
+		}
+	}
+	assignment := make([]int, size)
+	for receiver, giver := range giverOfReceiver {
+		assignment[giver] = receiver
+	}
+	return assignment, true
+}
+
+// hasAssignment tells whether a perfect matching between givers and receivers exists
+func hasAssignment(allowed [][]bool) bool {
+
+	size := len(allowed)
+	giverOfReceiver := make([]int, size)
+	for receiver := range giverOfReceiver {
+		giverOfReceiver[receiver] = -1
+	}
+	candidates := make([][]int, size)
+	for giver := 0; giver < size; giver++ {
+		for receiver := 0; receiver < size; receiver++ {
+			if allowed[giver][receiver] {
+				candidates[giver] = append(candidates[giver], receiver)
+			}
+		}
+	}
+	for giver := 0; giver < size; giver++ {
+		if !augment(giver, candidates, giverOfReceiver, make([]bool, size)) {
+			return false
+		}
+	}
+	return true
+}
+
+// augment tries to find an augmenting path starting at giver (Kuhn's algorithm)
+func augment(giver int, candidates [][]int, giverOfReceiver []int, visited []bool) bool {
+
+	for _, receiver := range candidates[giver] {
+		if visited[receiver] {
+			continue
+		}
+		visited[receiver] = true
+		if giverOfReceiver[receiver] == -1 || augment(giverOfReceiver[receiver], candidates, giverOfReceiver, visited) {
+			giverOfReceiver[receiver] = giver
+			return true
+		}
+	}
+	return false
+}
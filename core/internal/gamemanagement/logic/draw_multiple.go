@@ -0,0 +1,274 @@
+package logic
+
+import (
+	"sort"
+
+	glogic "github.com/yoktobit/secretsanta/internal/general/logic"
+)
+
+// drawMultipleAssignment draws for every giver gifts different receivers, so that every receiver is gifted gifts times too.
+// The result maps every giver index to its receiver indices in ascending order.
+// With one gift per player this is the usual draw, a single cycle is only possible with one gift per player.
+func drawMultipleAssignment(allowed [][]bool, gifts int, rules drawRules, random glogic.Randomizer, budget *searchBudget) ([][]int, bool) {
+
+	if gifts <= 1 {
+		assignment, ok := drawAssignment(allowed, rules, random, budget)
+		if !ok {
+			return nil, false
+		}
+		receivers := make([][]int, len(assignment))
+		for giver, receiver := range assignment {
+			receivers[giver] = []int{receiver}
+		}
+		return receivers, true
+	}
+	if rules.singleCycle {
+		return nil, false
+	}
+	chosen, ok := findRegularAssignment(allowed, gifts, random)
+	if !ok {
+		return nil, false
+	}
+	if !walkRegularAssignment(allowed, chosen, rules.forbidMutualPairs, random) {
+		// the walk may miss assignments without mutual pairs, so only an exhaustive search can prove there are none
+		chosen, ok = searchRegularWithoutMutualPairs(allowed, gifts, random, budget)
+		if !ok {
+			return nil, false
+		}
+	}
+	receivers := make([][]int, len(chosen))
+	for giver := range chosen {
+		receivers[giver] = make([]int, 0, gifts)
+		for receiver, ok := range chosen[giver] {
+			if ok {
+				receivers[giver] = append(receivers[giver], receiver)
+			}
+		}
+	}
+	return receivers, true
+}
+
+// findRegularAssignment searches allowed pairs so that every giver and every receiver is part of exactly gifts pairs.
+// This is a maximum flow with capacity gifts at every giver and receiver, it is found by augmenting paths.
+// If a giver can not be augmented, no such assignment exists at all.
+func findRegularAssignment(allowed [][]bool, gifts int, random glogic.Randomizer) ([][]bool, bool) {
+
+	size := len(allowed)
+	chosen := make([][]bool, size)
+	for giver := range chosen {
+		chosen[giver] = make([]bool, size)
+	}
+	candidates := make([][]int, size)
+	for giver := 0; giver < size; giver++ {
+		for _, receiver := range random.Perm(size) {
+			if allowed[giver][receiver] {
+				candidates[giver] = append(candidates[giver], receiver)
+			}
+		}
+	}
+	load := make([]int, size)
+	for round := 0; round < gifts; round++ {
+		for _, giver := range random.Perm(size) {
+			if !augmentRegular(giver, gifts, candidates, chosen, load, make([]bool, size)) {
+				return nil, false
+			}
+		}
+	}
+	return chosen, true
+}
+
+// augmentRegular adds a pair for giver. A receiver which already has enough givers passes
+// one of its givers on to another receiver, like Kuhn's algorithm does for single pairs.
+func augmentRegular(giver int, gifts int, candidates [][]int, chosen [][]bool, load []int, visited []bool) bool {
+
+	for _, receiver := range candidates[giver] {
+		if visited[receiver] || chosen[giver][receiver] {
+			continue
+		}
+		visited[receiver] = true
+		if load[receiver] < gifts {
+			chosen[giver][receiver] = true
+			load[receiver]++
+			return true
+		}
+		for other := range chosen {
+			if chosen[other][receiver] && augmentRegular(other, gifts, candidates, chosen, load, visited) {
+				chosen[other][receiver] = false
+				chosen[giver][receiver] = true
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// walkRegularAssignment exchanges the receivers of random pairs of pairs, which keeps the number of gifts of every player.
+// Every exchange is as likely as its reverse, but the number of exchanges gives no bound on how close the result gets
+// to uniform. If mutual pairs are forbidden, exchanges adding mutual pairs are refused and the walk continues
+// until all mutual pairs are gone. false is returned if that does not happen in time, which does not prove
+// that there is no assignment without mutual pairs.
+func walkRegularAssignment(allowed [][]bool, chosen [][]bool, forbidMutualPairs bool, random glogic.Randomizer) bool {
+
+	size := len(chosen)
+	pairs := make([][2]int, 0)
+	for giver := range chosen {
+		for receiver, ok := range chosen[giver] {
+			if ok {
+				pairs = append(pairs, [2]int{giver, receiver})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0] || pairs[i][0] == pairs[j][0] && pairs[i][1] < pairs[j][1]
+	})
+	mutualPairs := 0
+	if forbidMutualPairs {
+		mutualPairs = countMutualPairs(chosen)
+	}
+	steps := 10 * size * len(pairs)
+	for step := 0; step < steps || mutualPairs > 0 && step < maxRejections*steps; step++ {
+		i, j := random.NextInt(len(pairs)), random.NextInt(len(pairs))
+		a, x := pairs[i][0], pairs[i][1]
+		b, y := pairs[j][0], pairs[j][1]
+		if a == b || x == y || !allowed[a][y] || !allowed[b][x] || chosen[a][y] || chosen[b][x] {
+			continue
+		}
+		before := 0
+		if forbidMutualPairs {
+			before = mutualPairsAround(chosen, a, b, x, y)
+		}
+		chosen[a][x], chosen[b][y], chosen[a][y], chosen[b][x] = false, false, true, true
+		if forbidMutualPairs {
+			delta := mutualPairsAround(chosen, a, b, x, y) - before
+			if delta > 0 {
+				chosen[a][x], chosen[b][y], chosen[a][y], chosen[b][x] = true, true, false, false
+				continue
+			}
+			mutualPairs += delta
+		}
+		pairs[i][1], pairs[j][1] = y, x
+	}
+	return mutualPairs == 0
+}
+
+// searchRegularWithoutMutualPairs searches allowed pairs without mutual pairs so that every giver and every receiver
+// is part of exactly gifts pairs, by randomized backtracking. Every giver picks its receivers in the order of a random
+// permutation, and partial assignments which can not be completed by a flow are cut off early, see canCompleteRegular.
+// The search is exhaustive, so false is only returned if no such assignment exists at all or if the budget is exhausted.
+func searchRegularWithoutMutualPairs(allowed [][]bool, gifts int, random glogic.Randomizer, budget *searchBudget) ([][]bool, bool) {
+
+	size := len(allowed)
+	chosen := make([][]bool, size)
+	order := make([][]int, size)
+	for giver := range chosen {
+		chosen[giver] = make([]bool, size)
+		order[giver] = random.Perm(size)
+	}
+	load := make([]int, size)
+	var extend func(giver int, picked int, from int) bool
+	extend = func(giver int, picked int, from int) bool {
+		if picked == gifts {
+			giver, picked, from = giver+1, 0, 0
+		}
+		if giver == size {
+			return true
+		}
+		for position := from; position < size; position++ {
+			receiver := order[giver][position]
+			if !allowed[giver][receiver] || load[receiver] == gifts || chosen[receiver][giver] {
+				continue
+			}
+			chosen[giver][receiver] = true
+			load[receiver]++
+			// checking whether the rest can be completed augments every remaining gift once
+			if !budget.spend(size * size * gifts) {
+				return false
+			}
+			if canCompleteRegular(allowed, gifts, order, chosen, load, giver, picked+1, position+1) && extend(giver, picked+1, position+1) {
+				return true
+			}
+			chosen[giver][receiver] = false
+			load[receiver]--
+			if budget.exhausted {
+				return false
+			}
+		}
+		return false
+	}
+	if !extend(0, 0, 0) {
+		return nil, false
+	}
+	return chosen, true
+}
+
+// canCompleteRegular tells whether the remaining gifts can still be handed out: giver has picked gifts so far and
+// may only pick receivers from position from on in its order, the givers after it have picked none. Mutual pairs
+// with the pairs chosen so far are left out, mutual pairs among the remaining gifts are not checked.
+func canCompleteRegular(allowed [][]bool, gifts int, order [][]int, chosen [][]bool, load []int, giver int, picked int, from int) bool {
+
+	size := len(allowed)
+	candidates := make([][]int, size)
+	for other := giver; other < size; other++ {
+		start := 0
+		if other == giver {
+			start = from
+		}
+		for _, receiver := range order[other][start:] {
+			if allowed[other][receiver] && !chosen[other][receiver] && !chosen[receiver][other] {
+				candidates[other] = append(candidates[other], receiver)
+			}
+		}
+	}
+	// the pairs chosen so far only count by their load, so augmenting paths can not move them
+	remaining := make([][]bool, size)
+	for other := range remaining {
+		remaining[other] = make([]bool, size)
+	}
+	remainingLoad := append([]int(nil), load...)
+	for other := giver; other < size; other++ {
+		missing := gifts
+		if other == giver {
+			missing = gifts - picked
+		}
+		for ; missing > 0; missing-- {
+			if !augmentRegular(other, gifts, candidates, remaining, remainingLoad, make([]bool, size)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// countMutualPairs counts the players gifting each other
+func countMutualPairs(chosen [][]bool) int {
+
+	count := 0
+	for a := range chosen {
+		for b := a + 1; b < len(chosen); b++ {
+			if chosen[a][b] && chosen[b][a] {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// mutualPairsAround counts the mutual pairs among the players touched by an exchange
+func mutualPairsAround(chosen [][]bool, a, b, x, y int) int {
+
+	players := []int{a, b, x, y}
+	count := 0
+	seen := make(map[[2]int]bool)
+	for _, u := range players {
+		for _, v := range players {
+			if u >= v || seen[[2]int{u, v}] {
+				continue
+			}
+			seen[[2]int{u, v}] = true
+			if chosen[u][v] && chosen[v][u] {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,207 @@
+package logic
+
+import (
+	"sort"
+
+	glogic "github.com/yoktobit/secretsanta/internal/general/logic"
+)
+
+// preferenceSamples is how many assignments are drawn and improved if the best assignment can not be computed directly
+const preferenceSamples = 100
+
+// drawPreferredAssignment draws an assignment with the lowest total penalty, penalty[giver][receiver] is added
+// for every pair in the assignment. The total penalty of the result is returned as score.
+// With one gift per player, no rule preventing it and up to maxExactDrawSize players, the result is drawn uniformly
+// among all optimal assignments.
+// Otherwise the best of several drawn assignments, each improved by exchanging receivers, is returned.
+func drawPreferredAssignment(allowed [][]bool, penalty [][]int, gifts int, rules drawRules, random glogic.Randomizer, budget *searchBudget) ([][]int, int, bool) {
+
+	if !hasPenalty(penalty) {
+		assignment, ok := drawMultipleAssignment(allowed, gifts, rules, random, budget)
+		return assignment, 0, ok
+	}
+	if gifts <= 1 {
+		if optimal, ok := optimalPairs(allowed, penalty); ok {
+			if assignment, ok := drawMultipleAssignment(optimal, gifts, rules, random, budget); ok {
+				return assignment, assignmentPenalty(assignment, penalty), true
+			}
+		}
+	}
+	var best [][]int
+	bestScore := 0
+	for sample := 0; sample < preferenceSamples; sample++ {
+		assignment, ok := drawMultipleAssignment(allowed, gifts, rules, random, budget)
+		if !ok && best != nil {
+			// a later draw only fails once the budget is exhausted, the best assignment so far is still valid
+			break
+		}
+		if !ok {
+			// the draws are exhaustive, if the first fails all others do too
+			return nil, 0, false
+		}
+		if !rules.singleCycle {
+			improveAssignment(assignment, allowed, penalty, rules.forbidMutualPairs)
+		}
+		score := assignmentPenalty(assignment, penalty)
+		if best == nil || score < bestScore {
+			best, bestScore = assignment, score
+		}
+		if bestScore == 0 {
+			break
+		}
+	}
+	return best, bestScore, true
+}
+
+// optimalPairs returns the allowed pairs which are part of at least one assignment with the lowest total penalty.
+// Every assignment using only these pairs has the lowest total penalty, so drawing among them uniformly
+// draws uniformly among all optimal assignments. false is returned if there is no valid assignment at all.
+func optimalPairs(allowed [][]bool, penalty [][]int) ([][]bool, bool) {
+
+	if !hasAssignment(allowed) {
+		return nil, false
+	}
+	rowPotential, columnPotential := assignmentPotentials(allowed, penalty)
+	optimal := make([][]bool, len(allowed))
+	for giver := range allowed {
+		optimal[giver] = make([]bool, len(allowed))
+		for receiver, ok := range allowed[giver] {
+			optimal[giver][receiver] = ok && int64(penalty[giver][receiver]) == rowPotential[giver]+columnPotential[receiver]
+		}
+	}
+	return optimal, true
+}
+
+// assignmentPotentials solves the assignment problem with the Hungarian method and returns its dual solution.
+// The potentials fulfill row[giver]+column[receiver] <= penalty for every allowed pair, with equality exactly
+// on the pairs an optimal assignment may use. Forbidden pairs get a penalty too high to ever be chosen.
+func assignmentPotentials(allowed [][]bool, penalty [][]int) ([]int64, []int64) {
+
+	size := len(allowed)
+	forbidden := int64(1)
+	for giver := range penalty {
+		for _, value := range penalty[giver] {
+			forbidden += int64(value)
+		}
+	}
+	forbidden *= int64(size + 1)
+	cost := func(giver int, receiver int) int64 {
+		if !allowed[giver][receiver] {
+			return forbidden
+		}
+		return int64(penalty[giver][receiver])
+	}
+	// the arrays are 1-based, index 0 is a virtual column used to start every row
+	row := make([]int64, size+1)
+	column := make([]int64, size+1)
+	rowOfColumn := make([]int, size+1)
+	way := make([]int, size+1)
+	for current := 1; current <= size; current++ {
+		rowOfColumn[0] = current
+		column0 := 0
+		minimum := make([]int64, size+1)
+		used := make([]bool, size+1)
+		for j := range minimum {
+			minimum[j] = -1
+		}
+		for {
+			used[column0] = true
+			giver, delta, next := rowOfColumn[column0], int64(-1), 0
+			for j := 1; j <= size; j++ {
+				if used[j] {
+					continue
+				}
+				reduced := cost(giver-1, j-1) - row[giver] - column[j]
+				if minimum[j] < 0 || reduced < minimum[j] {
+					minimum[j] = reduced
+					way[j] = column0
+				}
+				if delta < 0 || minimum[j] < delta {
+					delta = minimum[j]
+					next = j
+				}
+			}
+			for j := 0; j <= size; j++ {
+				if used[j] {
+					row[rowOfColumn[j]] += delta
+					column[j] -= delta
+				} else {
+					minimum[j] -= delta
+				}
+			}
+			column0 = next
+			if rowOfColumn[column0] == 0 {
+				break
+			}
+		}
+		for column0 != 0 {
+			previous := way[column0]
+			rowOfColumn[column0] = rowOfColumn[previous]
+			column0 = previous
+		}
+	}
+	return row[1:], column[1:]
+}
+
+// improveAssignment exchanges the receivers of two pairs as long as this lowers the total penalty
+func improveAssignment(assignment [][]int, allowed [][]bool, penalty [][]int, forbidMutualPairs bool) {
+
+	size := len(assignment)
+	chosen := make([][]bool, size)
+	for giver := range chosen {
+		chosen[giver] = make([]bool, size)
+		for _, receiver := range assignment[giver] {
+			chosen[giver][receiver] = true
+		}
+	}
+	for improved := true; improved; {
+		improved = false
+		for a := 0; a < size; a++ {
+			for i, x := range assignment[a] {
+				for b := a + 1; b < size; b++ {
+					for j, y := range assignment[b] {
+						if x == y || !allowed[a][y] || !allowed[b][x] || chosen[a][y] || chosen[b][x] {
+							continue
+						}
+						if penalty[a][y]+penalty[b][x] >= penalty[a][x]+penalty[b][y] {
+							continue
+						}
+						if forbidMutualPairs && (chosen[y][a] || chosen[x][b]) {
+							continue
+						}
+						chosen[a][x], chosen[b][y], chosen[a][y], chosen[b][x] = false, false, true, true
+						assignment[a][i], assignment[b][j] = y, x
+						x = y
+						improved = true
+					}
+				}
+			}
+		}
+	}
+	for giver := range assignment {
+		sort.Ints(assignment[giver])
+	}
+}
+
+func hasPenalty(penalty [][]int) bool {
+
+	for giver := range penalty {
+		for _, value := range penalty[giver] {
+			if value != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func assignmentPenalty(assignment [][]int, penalty [][]int) int {
+
+	total := 0
+	for giver, receivers := range assignment {
+		for _, receiver := range receivers {
+			total += penalty[giver][receiver]
+		}
+	}
+	return total
+}
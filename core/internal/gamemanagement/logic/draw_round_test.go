@@ -0,0 +1,106 @@
+package logic_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/errors"
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+)
+
+var _ = Describe("DrawRound", func() {
+
+	var gamemanagement logic.Gamemanagement
+	var mock sqlmock.Sqlmock
+	code := "ABC"
+
+	BeforeEach(func() {
+		var c dataaccess.Connection
+		c, mock = dataaccess.NewMockConnection()
+		gamemanagement = newTestGamemanagement(c, nil, nil)
+	})
+
+	expectGame := func(status string) {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, status))
+	}
+	// expectRound expects the second round of the game, drawn in the circle Max→Moritz→Susi→Max
+	expectRound := func() {
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "number", "draw_audit_id"}).AddRow(7, 1, 2, 5))
+		mock.ExpectQuery("SELECT").WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"id", "draw_round_id", "giver_id", "receiver_id"}).AddRow(1, 7, 1, 2).AddRow(2, 7, 2, 3).AddRow(3, 7, 3, 1))
+	}
+	expectDrawContext := func(players *sqlmock.Rows, exceptions *sqlmock.Rows) {
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(players)
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(exceptions)
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+	}
+	playerRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready")
+	}
+
+	It("should archive the lots as the next round and clear them", func() {
+		expectGame("Drawn")
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "giver_id", "receiver_id"}).AddRow(1, 1, 1, 2).AddRow(2, 1, 2, 1))
+		expectRound()
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "seed"}).AddRow(6, 1, 42))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "draw_rounds"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 3, 6).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+		mock.ExpectQuery(`INSERT INTO "round_assignments"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 8, 1, 2, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 8, 2, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectExec(`UPDATE "assignments"`).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(`UPDATE "games"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", code, "Reset", "", false, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		err := gamemanagement.ResetGame(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("should restore the latest round with its audit", func() {
+		expectGame("Reset")
+		expectRound()
+		expectDrawContext(playerRows(), sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+		mock.ExpectQuery("SELECT").WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "algorithm_version", "seed", "commitment", "input", "result", "repairs"}).AddRow(5, 1, "1", 42, "abc", "{}", "[[1]]", "[]"))
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "assignments"`).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`INSERT INTO "assignments"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 1, 2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectQuery(`INSERT INTO "assignments"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, 3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectQuery(`INSERT INTO "assignments"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 3, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+		mock.ExpectQuery(`INSERT INTO "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "1", 42, "abc", "{}", "[[1]]", "[]").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+		mock.ExpectExec(`UPDATE "round_assignments"`).WithArgs(sqlmock.AnyArg(), 7).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(`UPDATE "draw_rounds"`).WithArgs(sqlmock.AnyArg(), 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE "games"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", code, "Drawn", "", false, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		err := gamemanagement.RestoreGame(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("should not restore a round if a player joined since", func() {
+		expectGame("Reset")
+		expectRound()
+		expectDrawContext(playerRows().AddRow(4, "Strolch", 1, "Ready"), sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+		err := gamemanagement.RestoreGame(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(errors.ErrDrawRoundOutdated))
+	})
+	It("should not restore a round breaking a new exception", func() {
+		expectGame("Reset")
+		expectRound()
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(playerRows())
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 1, 2))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max"))
+		mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Moritz"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+		err := gamemanagement.RestoreGame(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(errors.ErrDrawRoundOutdated))
+	})
+	It("should only restore a reset game", func() {
+		expectGame("Ready")
+		err := gamemanagement.RestoreGame(code)
+		Expect(err).To(MatchError(errors.ErrGameNotReset))
+	})
+	It("should fail without an archived round", func() {
+		expectGame("Reset")
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "number"}))
+		err := gamemanagement.RestoreGame(code)
+		Expect(err).To(HaveOccurred())
+	})
+})
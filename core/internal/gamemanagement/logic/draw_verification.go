@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	glogic "github.com/yoktobit/secretsanta/internal/general/logic"
+)
+
+// DrawDistribution describes how often every assignment came out of a series of draws
+type DrawDistribution struct {
+	Runs int
+	// ValidAssignments is the number of all valid assignments, 0 if there are too many players to count them
+	ValidAssignments int
+	// Counts maps an assignment, written as the receiver index of every giver (e.g. "1,2,0"), to its number of draws
+	Counts map[string]int
+	// ChiSquare is Pearson's chi-squared statistic against the uniform distribution with ValidAssignments-1 degrees of freedom
+	ChiSquare float64
+	// Uniform tells whether the draw picks every valid assignment with equal probability. This only holds up to
+	// maxExactDrawSize (20) players, larger draws use a random walk whose distribution is not uniform.
+	Uniform bool
+}
+
+// VerifyDrawDistribution draws the lots runs times with the given randomizer and reports the distribution.
+// allowed[giver][receiver] tells whether giver may gift receiver. A seeded randomizer makes the result reproducible.
+func VerifyDrawDistribution(allowed [][]bool, runs int, random glogic.Randomizer) DrawDistribution {
+
+	distribution := DrawDistribution{Runs: runs, Counts: make(map[string]int), Uniform: len(allowed) <= maxExactDrawSize}
+	if len(allowed) <= maxExactDrawSize {
+		distribution.ValidAssignments = newAssignmentCounter(allowed).count(0)
+	}
+	if distribution.ValidAssignments == 0 && len(allowed) <= maxExactDrawSize {
+		return distribution
+	}
+	for run := 0; run < runs; run++ {
+		assignment, ok := sampleAssignment(allowed, random)
+		if !ok {
+			break
+		}
+		distribution.Counts[assignmentKey(assignment)]++
+	}
+	if distribution.ValidAssignments > 0 {
+		expected := float64(runs) / float64(distribution.ValidAssignments)
+		keys := make([]string, 0, len(distribution.Counts))
+		for key := range distribution.Counts {
+			keys = append(keys, key)
+		}
+		// a fixed summation order keeps the statistic reproducible
+		sort.Strings(keys)
+		for _, key := range keys {
+			deviation := float64(distribution.Counts[key]) - expected
+			distribution.ChiSquare += deviation * deviation / expected
+		}
+		distribution.ChiSquare += float64(distribution.ValidAssignments-len(distribution.Counts)) * expected
+	}
+	return distribution
+}
+
+func assignmentKey(assignment []int) string {
+
+	receivers := make([]string, len(assignment))
+	for giver, receiver := range assignment {
+		receivers[giver] = strconv.Itoa(receiver)
+	}
+	return strings.Join(receivers, ",")
+}
@@ -0,0 +1,67 @@
+package logic_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic"
+	gl "github.com/yoktobit/secretsanta/internal/general/logic"
+)
+
+func newAllowedMatrix(size int) [][]bool {
+	allowed := make([][]bool, size)
+	for giver := range allowed {
+		allowed[giver] = make([]bool, size)
+		for receiver := range allowed[giver] {
+			allowed[giver][receiver] = giver != receiver
+		}
+	}
+	return allowed
+}
+
+var _ = Describe("DrawDistribution", func() {
+
+	It("should draw all derangements uniformly", func() {
+		distribution := logic.VerifyDrawDistribution(newAllowedMatrix(4), 9000, gl.NewSeededRandomizer(42))
+		Expect(distribution.Runs).To(Equal(9000))
+		Expect(distribution.ValidAssignments).To(Equal(9))
+		Expect(distribution.Counts).To(HaveLen(9))
+		// 99.9% quantile of the chi-squared distribution with 8 degrees of freedom
+		Expect(distribution.ChiSquare).To(BeNumerically("<", 26.12))
+		Expect(distribution.Uniform).To(BeTrue())
+	})
+	It("should draw uniformly with exceptions", func() {
+		allowed := newAllowedMatrix(5)
+		allowed[0][1] = false
+		allowed[1][0] = false
+		allowed[2][3] = false
+		distribution := logic.VerifyDrawDistribution(allowed, 10000, gl.NewSeededRandomizer(7))
+		Expect(distribution.ValidAssignments).To(BeNumerically(">", 1))
+		Expect(distribution.Counts).To(HaveLen(distribution.ValidAssignments))
+		for key := range distribution.Counts {
+			receivers := strings.Split(key, ",")
+			Expect(receivers[0]).ToNot(Equal("1"))
+			Expect(receivers[1]).ToNot(Equal("0"))
+			Expect(receivers[2]).ToNot(Equal("3"))
+		}
+	})
+	It("should be reproducible with the same seed", func() {
+		first := logic.VerifyDrawDistribution(newAllowedMatrix(6), 100, gl.NewSeededRandomizer(1))
+		second := logic.VerifyDrawDistribution(newAllowedMatrix(6), 100, gl.NewSeededRandomizer(1))
+		Expect(first).To(Equal(second))
+	})
+	It("should report no valid assignment if there is none", func() {
+		allowed := newAllowedMatrix(2)
+		allowed[0][1] = false
+		distribution := logic.VerifyDrawDistribution(allowed, 100, gl.NewSeededRandomizer(1))
+		Expect(distribution.ValidAssignments).To(BeZero())
+		Expect(distribution.Counts).To(BeEmpty())
+	})
+	It("should not claim a uniform draw for more than 20 players", func() {
+		distribution := logic.VerifyDrawDistribution(newAllowedMatrix(21), 10, gl.NewSeededRandomizer(1))
+		Expect(distribution.ValidAssignments).To(BeZero())
+		Expect(distribution.Counts).ToNot(BeEmpty())
+		Expect(distribution.Uniform).To(BeFalse())
+	})
+})
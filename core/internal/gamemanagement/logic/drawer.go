@@ -0,0 +1,281 @@
+package logic
+
+import (
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/dataaccess"
+	gerr "github.com/yoktobit/secretsanta/internal/gamemanagement/logic/errors"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+	glogic "github.com/yoktobit/secretsanta/internal/general/logic"
+)
+
+// DefaultDrawStrategy is the name of the built-in draw strategy, games without a strategy use it
+const DefaultDrawStrategy = "default"
+
+// DrawContext is everything known about a game when its draw input is built
+type DrawContext struct {
+	Game        *dataaccess.Game
+	Players     []*dataaccess.Player
+	Exceptions  []*dataaccess.PlayerException
+	Preferences []*dataaccess.PlayerPreference
+	// history holds the pairings of previous years, last year first
+	history [][]pairing
+}
+
+// Constraint restricts a draw. It forbids pairs in input.Allowed, or marks pairs to be avoided in input.History
+// or input.Penalty. Players are referred to by their index in context.Players.
+type Constraint interface {
+	Apply(context *DrawContext, input *to.DrawInputTo)
+}
+
+// DrawResult is the outcome of a draw. Assignment maps every giver index to its receiver indices in ascending order,
+// Score is the total penalty and Years the number of previous years whose pairings could be avoided.
+// GaveUp tells a draw which was too costly to search apart from one which is impossible.
+type DrawResult struct {
+	Assignment [][]int
+	Score      int
+	Years      int
+	Ok         bool
+	GaveUp     bool
+}
+
+// Drawer is a draw strategy. The same input and random numbers must always result in the same lots,
+// otherwise draws can not be verified.
+type Drawer interface {
+	Name() string
+	Draw(input to.DrawInputTo, random glogic.Randomizer) DrawResult
+}
+
+// DrawStrategies holds the constraints every draw is restricted by and the draw strategies a game can choose from
+type DrawStrategies struct {
+	constraints []Constraint
+	drawers     map[string]Drawer
+}
+
+// NewDrawStrategies creates draw strategies from the given constraints and drawers, a drawer with the
+// name of the default strategy replaces the built-in one. Register own constraints and drawers in wire.go.
+func NewDrawStrategies(constraints []Constraint, drawers []Drawer) *DrawStrategies {
+
+	drawStrategies := &DrawStrategies{constraints: constraints, drawers: make(map[string]Drawer)}
+	drawStrategies.drawers[DefaultDrawStrategy] = defaultDrawer{}
+	for _, drawer := range drawers {
+		drawStrategies.drawers[drawer.Name()] = drawer
+	}
+	return drawStrategies
+}
+
+// NewDefaultDrawStrategies creates the built-in constraints and draw strategy
+func NewDefaultDrawStrategies() *DrawStrategies {
+
+	return NewDrawStrategies(DefaultConstraints(), nil)
+}
+
+// DefaultConstraints are the built-in constraints: nobody gifts himself/herself, the exceptions, the households,
+// the pairings of previous years and the preferences
+func DefaultConstraints() []Constraint {
+
+	return []Constraint{noSelfConstraint{}, exceptionConstraint{}, householdConstraint{}, noRepeatConstraint{}, preferenceConstraint{}}
+}
+
+// Drawer returns the draw strategy of the given name, an empty name is the default strategy
+func (drawStrategies *DrawStrategies) Drawer(name string) (Drawer, error) {
+
+	if name == "" {
+		name = DefaultDrawStrategy
+	}
+	drawer, found := drawStrategies.drawers[name]
+	if !found {
+		return nil, gerr.ErrUnknownDrawStrategy
+	}
+	return drawer, nil
+}
+
+// Input builds the input of a draw by applying all constraints, starting with every pair allowed
+func (drawStrategies *DrawStrategies) Input(context *DrawContext) to.DrawInputTo {
+
+	size := len(context.Players)
+	game := context.Game
+	input := to.DrawInputTo{Players: make([]string, size), Allowed: make([][]bool, size), History: make([][][2]int, 0), Penalty: make([][]int, size), GiftsPerPlayer: game.GiftsPerPlayer, SingleCycle: game.DrawMode == dataaccess.DrawModeSingleCycle.String(), ForbidMutualPairs: game.ForbidMutualPairs, Strategy: game.DrawStrategy}
+	if input.GiftsPerPlayer < 1 {
+		input.GiftsPerPlayer = 1
+	}
+	for i, player := range context.Players {
+		input.Players[i] = player.Name
+		input.Allowed[i] = make([]bool, size)
+		input.Penalty[i] = make([]int, size)
+		for j := range input.Allowed[i] {
+			input.Allowed[i][j] = true
+		}
+	}
+	for _, constraint := range drawStrategies.constraints {
+		constraint.Apply(context, &input)
+	}
+	return input
+}
+
+// Verify checks a revealed draw offline: the commitment has to match the algorithm version, seed and input,
+// and drawing again with the seed has to result in exactly the published lots.
+func (drawStrategies *DrawStrategies) Verify(audit to.DrawAuditTo) error {
+
+	if !audit.Revealed {
+		return gerr.ErrSeedNotRevealed
+	}
+	newRandomizer, found := drawRandomizers[audit.AlgorithmVersion]
+	if !found {
+		return gerr.ErrUnknownDrawAlgorithm
+	}
+	drawer, err := drawStrategies.Drawer(audit.Input.Strategy)
+	if err != nil {
+		return gerr.ErrUnknownDrawAlgorithm
+	}
+	commitment, err := drawCommitment(audit.AlgorithmVersion, audit.Seed, audit.Input)
+	if err != nil {
+		return err
+	}
+	if commitment != audit.Commitment {
+		return gerr.ErrDrawVerificationFailed
+	}
+	result := drawer.Draw(audit.Input, newRandomizer(audit.Seed))
+	if !result.Ok || len(result.Assignment) != len(audit.Result) {
+		return gerr.ErrDrawVerificationFailed
+	}
+	for giver, receivers := range result.Assignment {
+		if len(receivers) != len(audit.Result[giver]) {
+			return gerr.ErrDrawVerificationFailed
+		}
+		for i, receiver := range receivers {
+			if audit.Result[giver][i] != receiver {
+				return gerr.ErrDrawVerificationFailed
+			}
+		}
+	}
+	return nil
+}
+
+// checkAssignment makes sure that a drawn assignment follows the input, whatever strategy drew it
+func checkAssignment(input to.DrawInputTo, assignment [][]int) bool {
+
+	size := len(input.Players)
+	if len(assignment) != size {
+		return false
+	}
+	gifts := input.GiftsPerPlayer
+	chosen := make([][]bool, size)
+	for giver := range chosen {
+		chosen[giver] = make([]bool, size)
+	}
+	received := make([]int, size)
+	for giver, receivers := range assignment {
+		if len(receivers) != gifts {
+			return false
+		}
+		for _, receiver := range receivers {
+			if receiver < 0 || receiver >= size || !input.Allowed[giver][receiver] || chosen[giver][receiver] {
+				return false
+			}
+			chosen[giver][receiver] = true
+			received[receiver]++
+		}
+	}
+	for _, count := range received {
+		if count != gifts {
+			return false
+		}
+	}
+	if input.ForbidMutualPairs && countMutualPairs(chosen) > 0 {
+		return false
+	}
+	if input.SingleCycle && size > 0 {
+		length := 1
+		for current := assignment[0][0]; current != 0; current = assignment[current][0] {
+			length++
+			if length > size {
+				return false
+			}
+		}
+		return length == size
+	}
+	return true
+}
+
+// defaultDrawer draws among the assignments with the lowest penalty, uniformly up to maxExactDrawSize players, see runDraw
+type defaultDrawer struct{}
+
+func (drawer defaultDrawer) Name() string {
+	return DefaultDrawStrategy
+}
+
+func (drawer defaultDrawer) Draw(input to.DrawInputTo, random glogic.Randomizer) DrawResult {
+
+	return runDraw(input, random)
+}
+
+// noSelfConstraint forbids players to gift themselves
+type noSelfConstraint struct{}
+
+func (constraint noSelfConstraint) Apply(context *DrawContext, input *to.DrawInputTo) {
+
+	for i := range input.Allowed {
+		input.Allowed[i][i] = false
+	}
+}
+
+// exceptionConstraint forbids the pairs of the exceptions of a game
+type exceptionConstraint struct{}
+
+func (constraint exceptionConstraint) Apply(context *DrawContext, input *to.DrawInputTo) {
+
+	index := playerIndices(context.Players)
+	for _, playerException := range context.Exceptions {
+		giver, giverFound := index[playerException.PlayerA.ID]
+		receiver, receiverFound := index[playerException.PlayerB.ID]
+		if giverFound && receiverFound {
+			input.Allowed[giver][receiver] = false
+		}
+	}
+}
+
+// householdConstraint forbids members of the same household to gift each other
+type householdConstraint struct{}
+
+func (constraint householdConstraint) Apply(context *DrawContext, input *to.DrawInputTo) {
+
+	for giver, giftee := range context.Players {
+		for receiver, gifted := range context.Players {
+			if giftee.HouseholdID != nil && gifted.HouseholdID != nil && *giftee.HouseholdID == *gifted.HouseholdID {
+				input.Allowed[giver][receiver] = false
+			}
+		}
+	}
+}
+
+// noRepeatConstraint avoids the pairings of previous years. They are only avoided as long as possible,
+// so they are not forbidden but handed to the drawer as history.
+type noRepeatConstraint struct{}
+
+func (constraint noRepeatConstraint) Apply(context *DrawContext, input *to.DrawInputTo) {
+
+	input.History = append(input.History, pairingIndices(context.Players, context.history)...)
+}
+
+// preferenceConstraint adds the weights of the preferences to the penalty of their pairs
+type preferenceConstraint struct{}
+
+func (constraint preferenceConstraint) Apply(context *DrawContext, input *to.DrawInputTo) {
+
+	index := playerIndices(context.Players)
+	for _, preference := range context.Preferences {
+		giver, giverFound := index[preference.PlayerAID]
+		receiver, receiverFound := index[preference.PlayerBID]
+		if giverFound && receiverFound {
+			input.Penalty[giver][receiver] += preference.Weight
+		}
+	}
+}
+
+func playerIndices(players []*dataaccess.Player) map[uint]int {
+
+	index := make(map[uint]int)
+	for i, player := range players {
+		index[player.ID] = i
+	}
+	return index
+}
@@ -0,0 +1,245 @@
+package logic_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	da "github.com/yoktobit/secretsanta/internal/gamemanagement/dataaccess"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/errors"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+	gl "github.com/yoktobit/secretsanta/internal/general/logic"
+)
+
+// firstNotLastConstraint forbids the first player to gift the last one
+type firstNotLastConstraint struct{}
+
+func (constraint firstNotLastConstraint) Apply(context *logic.DrawContext, input *to.DrawInputTo) {
+	input.Allowed[0][len(input.Allowed)-1] = false
+}
+
+// rotationDrawer lets every player gift the next one, or himself/herself if selfish
+type rotationDrawer struct {
+	selfish bool
+}
+
+func (drawer rotationDrawer) Name() string {
+	if drawer.selfish {
+		return "selfish"
+	}
+	return "rotation"
+}
+
+func (drawer rotationDrawer) Draw(input to.DrawInputTo, random gl.Randomizer) logic.DrawResult {
+	assignment := make([][]int, len(input.Players))
+	for giver := range assignment {
+		if drawer.selfish {
+			assignment[giver] = []int{giver}
+		} else {
+			assignment[giver] = []int{(giver + 1) % len(assignment)}
+		}
+	}
+	return logic.DrawResult{Assignment: assignment, Ok: true}
+}
+
+// gaveUpDrawer is a strategy whose search always gives up
+type gaveUpDrawer struct{}
+
+func (drawer gaveUpDrawer) Name() string {
+	return "gaveUp"
+}
+
+func (drawer gaveUpDrawer) Draw(input to.DrawInputTo, random gl.Randomizer) logic.DrawResult {
+	return logic.DrawResult{GaveUp: true}
+}
+
+// newDrawInput creates the input of a draw of size players, who may only gift the players allowed by gifts.
+// The draw forms a single circle or, if not, forbids mutual pairs.
+func newDrawInput(size int, singleCycle bool, gifts func(giver int, receiver int) bool) to.DrawInputTo {
+	input := to.DrawInputTo{Players: make([]string, size), Allowed: make([][]bool, size), History: make([][][2]int, 0), Penalty: make([][]int, size), GiftsPerPlayer: 1, SingleCycle: singleCycle, ForbidMutualPairs: !singleCycle}
+	for giver := range input.Allowed {
+		input.Allowed[giver] = make([]bool, size)
+		input.Penalty[giver] = make([]int, size)
+		for receiver := range input.Allowed[giver] {
+			input.Allowed[giver][receiver] = giver != receiver && gifts(giver, receiver)
+		}
+	}
+	return input
+}
+
+var _ = Describe("DrawStrategies", func() {
+
+	var gamemanagement logic.Gamemanagement
+	var mock sqlmock.Sqlmock
+	var drawStrategies *logic.DrawStrategies
+	code := "ABC"
+
+	BeforeEach(func() {
+		var c dataaccess.Connection
+		c, mock = dataaccess.NewMockConnection()
+		constraints := append(logic.DefaultConstraints(), firstNotLastConstraint{})
+		drawStrategies = logic.NewDrawStrategies(constraints, []logic.Drawer{rotationDrawer{}, rotationDrawer{selfish: true}, gaveUpDrawer{}})
+		gamemanagement = newTestGamemanagement(c, nil, drawStrategies)
+	})
+
+	expectDrawQueries := func(strategy string) {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "draw_strategy"}).AddRow(1, code, "Ready", strategy))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+	}
+
+	It("should apply the built-in and own constraints", func() {
+		household := uint(1)
+		players := []*da.Player{{Name: "Max"}, {Name: "Moritz"}, {Name: "Susi"}, {Name: "Strolch"}}
+		for i, player := range players {
+			player.ID = uint(i + 1)
+		}
+		players[1].HouseholdID = &household
+		players[2].HouseholdID = &household
+		exceptions := []*da.PlayerException{{PlayerA: *players[3], PlayerB: *players[0]}}
+		input := drawStrategies.Input(&logic.DrawContext{Game: &da.Game{}, Players: players, Exceptions: exceptions})
+		Expect(input.Players).To(Equal([]string{"Max", "Moritz", "Susi", "Strolch"}))
+		Expect(input.GiftsPerPlayer).To(Equal(1))
+		Expect(input.Allowed).To(Equal([][]bool{
+			{false, true, true, false},
+			{true, false, false, true},
+			{true, false, false, true},
+			{false, true, true, false},
+		}))
+	})
+	It("should draw with the strategy chosen by the game", func() {
+		expectDrawQueries("rotation")
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(false, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 1, 2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, 3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 3, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectQuery(`INSERT INTO "draw_audits"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		drawGameResponseTo, err := gamemanagement.DrawGame(to.DrawGameTo{GameCode: code})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drawGameResponseTo.Ok).To(BeTrue())
+	})
+	It("should refuse lots breaking the constraints", func() {
+		expectDrawQueries("selfish")
+		drawGameResponseTo, err := gamemanagement.DrawGame(to.DrawGameTo{GameCode: code})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drawGameResponseTo.Ok).To(BeFalse())
+	})
+	It("should fail to draw if the search gave up", func() {
+		expectDrawQueries("gaveUp")
+		_, err := gamemanagement.DrawGame(to.DrawGameTo{GameCode: code})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(errors.ErrDrawSearchGaveUp))
+	})
+	It("should fail to draw with an unknown strategy", func() {
+		expectDrawQueries("unknown")
+		_, err := gamemanagement.DrawGame(to.DrawGameTo{GameCode: code})
+		Expect(err).To(MatchError(errors.ErrUnknownDrawStrategy))
+	})
+	It("should not let a game choose an unknown strategy", func() {
+		strategy := "unknown"
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+		err := gamemanagement.UpdateGameSettings(to.UpdateGameSettingsTo{GameCode: code, DrawStrategy: &strategy})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(errors.ErrUnknownDrawStrategy))
+	})
+	It("should find the default strategy by an empty name", func() {
+		drawer, err := drawStrategies.Drawer("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drawer.Name()).To(Equal(logic.DefaultDrawStrategy))
+	})
+})
+
+var _ = Describe("Default draw strategy", func() {
+
+	var drawer logic.Drawer
+
+	BeforeEach(func() {
+		drawer, _ = logic.NewDefaultDrawStrategies().Drawer("")
+	})
+
+	It("should find a circle through sparse exceptions", func() {
+		// everybody may only gift the next two players, so most paths run into a dead end
+		input := newDrawInput(40, true, func(giver int, receiver int) bool {
+			return receiver == (giver+1)%40 || receiver == (giver+2)%40
+		})
+		result := drawer.Draw(input, gl.NewSeededRandomizer(1))
+		Expect(result.Ok).To(BeTrue())
+		Expect(result.GaveUp).To(BeFalse())
+		visited := map[int]bool{}
+		for current := 0; !visited[current]; current = result.Assignment[current][0] {
+			Expect(input.Allowed[current][result.Assignment[current][0]]).To(BeTrue())
+			visited[current] = true
+		}
+		Expect(visited).To(HaveLen(40))
+	})
+	It("should give up a circle search which takes too long", func() {
+		// two groups which only gift within the group have no common circle, but every path looks promising
+		input := newDrawInput(40, true, func(giver int, receiver int) bool {
+			return giver < 20 == (receiver < 20)
+		})
+		result := drawer.Draw(input, gl.NewSeededRandomizer(1))
+		Expect(result.Ok).To(BeFalse())
+		Expect(result.GaveUp).To(BeTrue())
+	})
+	It("should not give up a draw which is proven impossible", func() {
+		input := newDrawInput(40, true, func(giver int, receiver int) bool {
+			return receiver != 39
+		})
+		result := drawer.Draw(input, gl.NewSeededRandomizer(1))
+		Expect(result.Ok).To(BeFalse())
+		Expect(result.GaveUp).To(BeFalse())
+	})
+	It("should find an assignment without mutual pairs through sparse exceptions", func() {
+		input := newDrawInput(40, false, func(giver int, receiver int) bool {
+			return receiver == (giver+1)%40 || receiver == (giver+2)%40 || receiver == (giver+39)%40
+		})
+		result := drawer.Draw(input, gl.NewSeededRandomizer(1))
+		Expect(result.Ok).To(BeTrue())
+		Expect(result.GaveUp).To(BeFalse())
+		for giver, receivers := range result.Assignment {
+			Expect(input.Allowed[giver][receivers[0]]).To(BeTrue())
+			Expect(result.Assignment[receivers[0]][0]).ToNot(Equal(giver))
+		}
+	})
+	It("should give up a search for an assignment without mutual pairs which takes too long", func() {
+		// the last two players can only gift each other, which the search only notices at the end
+		input := newDrawInput(40, false, func(giver int, receiver int) bool {
+			return giver < 38 == (receiver < 38)
+		})
+		result := drawer.Draw(input, gl.NewSeededRandomizer(1))
+		Expect(result.Ok).To(BeFalse())
+		Expect(result.GaveUp).To(BeTrue())
+	})
+	It("should find several gifts without mutual pairs which the exchange walk misses", func() {
+		allowed := [][]bool{
+			{false, true, false, true, true},
+			{true, false, true, true, false},
+			{true, false, false, true, true},
+			{false, true, false, false, true},
+			{true, true, true, false, false},
+		}
+		input := newDrawInput(5, false, func(giver int, receiver int) bool {
+			return allowed[giver][receiver]
+		})
+		input.GiftsPerPlayer = 2
+		for seed := int64(0); seed < 5; seed++ {
+			result := drawer.Draw(input, gl.NewSeededRandomizer(seed))
+			Expect(result.Ok).To(BeTrue())
+			for giver, receivers := range result.Assignment {
+				Expect(receivers).To(HaveLen(2))
+				for _, receiver := range receivers {
+					Expect(allowed[giver][receiver]).To(BeTrue())
+					Expect(result.Assignment[receiver]).ToNot(ContainElement(giver))
+				}
+			}
+		}
+	})
+})
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrDrawAuditOutdated describes that the lots can not be matched to the players of the audited draw anymore
+var ErrDrawAuditOutdated = errors.New("Lots do not match the audited draw")
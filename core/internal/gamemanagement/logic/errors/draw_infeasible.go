@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrDrawInfeasible describes that no valid assignment exists for a game
+var ErrDrawInfeasible = errors.New("Game can not be drawn")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrDrawRoundOutdated describes that the players or exceptions changed since a draw round was drawn
+var ErrDrawRoundOutdated = errors.New("Draw round does not fit the game anymore")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrDrawSearchGaveUp describes that searching the lots of a game took too long, so it is unknown whether they can be drawn
+var ErrDrawSearchGaveUp = errors.New("Draw search gave up")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrDrawVerificationFailed describes that a draw does not match its commitment or can not be reproduced
+var ErrDrawVerificationFailed = errors.New("Draw could not be verified")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrEventDateMovedEarlier describes that the event date of a drawn game may only be moved later
+var ErrEventDateMovedEarlier = errors.New("Event date of a drawn game can only be moved later")
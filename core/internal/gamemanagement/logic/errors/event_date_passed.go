@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrEventDatePassed describes that the event date of a game lies in the past, which would reveal its draw right away
+var ErrEventDatePassed = errors.New("Event date has already passed")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrGameAlreadyDrawn describes that an operation is not possible because the lots have already been drawn
+var ErrGameAlreadyDrawn = errors.New("Game has already been drawn")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrGameNotDrawn describes that an operation needs lots which have not been drawn yet
+var ErrGameNotDrawn = errors.New("Game has not been drawn yet")
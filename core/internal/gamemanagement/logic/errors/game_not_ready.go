@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrGameNotReady describes that a game can not be drawn before all players are ready
+var ErrGameNotReady = errors.New("Game is not ready to be drawn")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrGameNotReset describes that only the draw of a reset game can be restored
+var ErrGameNotReset = errors.New("Game has not been reset")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrHouseholdAlreadyExists describes that a Household already exists
+var ErrHouseholdAlreadyExists = errors.New("Household already exists")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrInvalidExceptionMatrix describes that an exclusion matrix needs exactly one row and column for every listed player
+var ErrInvalidExceptionMatrix = errors.New("Exception matrix does not match the players")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrInvalidInvite describes that an invite token is unknown, has already been used or has expired
+var ErrInvalidInvite = errors.New("Invite is invalid")
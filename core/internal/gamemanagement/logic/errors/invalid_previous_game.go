@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrInvalidPreviousGame describes that a game can not be its own previous game
+var ErrInvalidPreviousGame = errors.New("Game can not be its own previous game")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrInvalidStatusTransition describes that a game can not change from its status to the requested one
+var ErrInvalidStatusTransition = errors.New("Status transition not allowed")
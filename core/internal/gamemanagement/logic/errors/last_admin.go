@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrLastAdmin describes that the only admin of a game can not lose the role or leave the game
+var ErrLastAdmin = errors.New("Game needs at least one admin")
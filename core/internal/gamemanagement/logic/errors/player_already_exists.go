@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrPlayerAlreadyExists describes that a Player of the same name already exists in the game
+var ErrPlayerAlreadyExists = errors.New("Player already exists")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrPlayerAlreadyRegistered describes that a player who has already chosen a password can not be invited again
+var ErrPlayerAlreadyRegistered = errors.New("Player has already registered")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrPlayerNotAdmin describes that only an admin can hand over the role
+var ErrPlayerNotAdmin = errors.New("Player is not an admin")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrPlayerNotFound describes that a Player does not exist in the game
+var ErrPlayerNotFound = errors.New("Player not found")
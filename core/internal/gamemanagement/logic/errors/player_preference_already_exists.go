@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrPlayerPreferenceAlreadyExists describes that a PlayerPreference already exists
+var ErrPlayerPreferenceAlreadyExists = errors.New("PlayerPreference already exists")
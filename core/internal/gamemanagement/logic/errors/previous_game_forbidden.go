@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrPreviousGameForbidden describes that only an admin of the previous game can link it
+var ErrPreviousGameForbidden = errors.New("Only an admin of the previous game can link it")
@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrSeedNotRevealed describes that a draw can not be verified before its seed is revealed
+var ErrSeedNotRevealed = errors.New("Seed of the draw is not revealed yet")
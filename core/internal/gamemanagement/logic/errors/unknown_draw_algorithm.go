@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrUnknownDrawAlgorithm describes that a draw was made with an algorithm version this build can not reproduce
+var ErrUnknownDrawAlgorithm = errors.New("Unknown draw algorithm version")
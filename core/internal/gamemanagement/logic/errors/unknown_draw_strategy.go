@@ -0,0 +1,6 @@
+package errors
+
+import "errors"
+
+// ErrUnknownDrawStrategy describes that a game chose a draw strategy which is not registered
+var ErrUnknownDrawStrategy = errors.New("Unknown draw strategy")
@@ -0,0 +1,106 @@
+package logic
+
+// drawConflict is a set of givers which together are allowed to gift fewer receivers than there are givers.
+// By Hall's theorem such a set exists exactly when there is no valid assignment.
+type drawConflict struct {
+	givers    []int
+	receivers []int
+}
+
+// findConflict returns a minimal set of givers blocking every assignment, or false if a valid assignment exists.
+// Minimal means that no smaller group of these givers is in conflict on its own.
+func findConflict(allowed [][]bool) (drawConflict, bool) {
+
+	givers := make([]int, len(allowed))
+	for giver := range givers {
+		givers[giver] = giver
+	}
+	conflict, found := conflictWithin(allowed, givers)
+	if !found {
+		return drawConflict{}, false
+	}
+	// if the givers without one of them are still in conflict, continue with that smaller conflict
+	for i := 0; i < len(conflict.givers); {
+		reduced := append(append(make([]int, 0, len(conflict.givers)-1), conflict.givers[:i]...), conflict.givers[i+1:]...)
+		if smaller, found := conflictWithin(allowed, reduced); found {
+			conflict = smaller
+			i = 0
+		} else {
+			i++
+		}
+	}
+	return conflict, true
+}
+
+// conflictWithin matches the given givers to receivers. If one of them remains unmatched,
+// all givers reachable from it by alternating paths are in conflict.
+func conflictWithin(allowed [][]bool, givers []int) (drawConflict, bool) {
+
+	size := len(allowed)
+	giverOfReceiver := make([]int, size)
+	for receiver := range giverOfReceiver {
+		giverOfReceiver[receiver] = -1
+	}
+	candidates := make([][]int, size)
+	for _, giver := range givers {
+		for receiver := 0; receiver < size; receiver++ {
+			if allowed[giver][receiver] {
+				candidates[giver] = append(candidates[giver], receiver)
+			}
+		}
+	}
+	unmatched := -1
+	for _, giver := range givers {
+		if !augment(giver, candidates, giverOfReceiver, make([]bool, size)) {
+			unmatched = giver
+			break
+		}
+	}
+	if unmatched == -1 {
+		return drawConflict{}, false
+	}
+	// every receiver reachable by alternating paths is matched, otherwise the path could be augmented,
+	// so the givers found this way have one receiver less than givers
+	inConflict := make([]bool, size)
+	inConflict[unmatched] = true
+	queue := []int{unmatched}
+	reached := make([]bool, size)
+	for len(queue) > 0 {
+		giver := queue[0]
+		queue = queue[1:]
+		for _, receiver := range candidates[giver] {
+			if reached[receiver] {
+				continue
+			}
+			reached[receiver] = true
+			next := giverOfReceiver[receiver]
+			if next >= 0 && !inConflict[next] {
+				inConflict[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	conflict := drawConflict{givers: make([]int, 0)}
+	for giver, ok := range inConflict {
+		if ok {
+			conflict.givers = append(conflict.givers, giver)
+		}
+	}
+	conflict.receivers = receiversOf(allowed, conflict.givers)
+	return conflict, true
+}
+
+// receiversOf returns all receivers which may be gifted by at least one of the givers
+func receiversOf(allowed [][]bool, givers []int) []int {
+
+	receivers := make([]int, 0)
+	for receiver := range allowed {
+		for _, giver := range givers {
+			if allowed[giver][receiver] {
+				receivers = append(receivers, receiver)
+				break
+			}
+		}
+	}
+	return receivers
+}
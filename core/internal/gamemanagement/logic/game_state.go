@@ -0,0 +1,125 @@
+package logic
+
+import (
+	"time"
+
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/dataaccess"
+	gerr "github.com/yoktobit/secretsanta/internal/gamemanagement/logic/errors"
+)
+
+// GameOperation is an operation on a game which is only allowed in some states
+type GameOperation int
+
+const (
+	// OperationEditPlayers adds or removes players, exceptions, households and preferences
+	OperationEditPlayers GameOperation = iota
+	// OperationUpdateSettings changes the draw options of a game
+	OperationUpdateSettings
+	// OperationUpdateDetails changes the title, description, event date or budget of a game
+	OperationUpdateDetails
+	// OperationRegisterPlayer registers the password of a player
+	OperationRegisterPlayer
+	// OperationDraw draws the lots
+	OperationDraw
+	// OperationRepairDraw changes the drawn lots for players who join late or drop out
+	OperationRepairDraw
+	// OperationReset resets a drawn game
+	OperationReset
+	// OperationRestore restores the previous draw of a reset game
+	OperationRestore
+)
+
+// gameTransitions are the status changes a game may go through:
+// Created → Waiting/Ready when players are added or all registered, Waiting → Ready when all players are registered,
+// Ready → Waiting when a player is added, Ready/Reset → Drawn when drawn or restored, Drawn → Reset
+// and Reset → Waiting when a player is added
+var gameTransitions = map[dataaccess.Status][]dataaccess.Status{
+	dataaccess.StatusCreated: {dataaccess.StatusWaiting, dataaccess.StatusReady},
+	dataaccess.StatusWaiting: {dataaccess.StatusReady},
+	dataaccess.StatusReady:   {dataaccess.StatusWaiting, dataaccess.StatusDrawn},
+	dataaccess.StatusDrawn:   {dataaccess.StatusReset},
+	dataaccess.StatusReset:   {dataaccess.StatusWaiting, dataaccess.StatusDrawn},
+}
+
+// gameOperations are the operations allowed in each status. Players of a drawn game are only added or removed
+// together with the repair of the lots, see AddPlayerToDrawnGame and RemovePlayerFromDrawnGame.
+// The event date of a game may only be set in the future and, once drawn, only moved later, see CheckEventDate.
+var gameOperations = map[dataaccess.Status][]GameOperation{
+	dataaccess.StatusCreated: {OperationEditPlayers, OperationUpdateSettings, OperationUpdateDetails, OperationRegisterPlayer},
+	dataaccess.StatusWaiting: {OperationEditPlayers, OperationUpdateSettings, OperationUpdateDetails, OperationRegisterPlayer},
+	dataaccess.StatusReady:   {OperationEditPlayers, OperationUpdateSettings, OperationUpdateDetails, OperationRegisterPlayer, OperationDraw},
+	dataaccess.StatusDrawn:   {OperationUpdateDetails, OperationRegisterPlayer, OperationRepairDraw, OperationReset},
+	dataaccess.StatusReset:   {OperationEditPlayers, OperationUpdateSettings, OperationUpdateDetails, OperationRegisterPlayer, OperationDraw, OperationRestore},
+}
+
+// GameStatus returns the status of a game, a game without status has just been created
+func GameStatus(game *dataaccess.Game) dataaccess.Status {
+
+	for status := dataaccess.StatusCreated; status <= dataaccess.StatusReset; status++ {
+		if game.Status == status.String() {
+			return status
+		}
+	}
+	return dataaccess.StatusCreated
+}
+
+// CanTransition tells whether a game may change from one status to another
+func CanTransition(from dataaccess.Status, to dataaccess.Status) bool {
+
+	for _, allowed := range gameTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionGame changes the status of a game if the transition is allowed, staying in the same status always is
+func TransitionGame(game *dataaccess.Game, status dataaccess.Status) error {
+
+	current := GameStatus(game)
+	if current != status && !CanTransition(current, status) {
+		return gerr.ErrInvalidStatusTransition
+	}
+	game.Status = status.String()
+	return nil
+}
+
+// CheckGameOperation tells whether an operation is allowed in the status of a game and why not otherwise
+func CheckGameOperation(game *dataaccess.Game, operation GameOperation) error {
+
+	status := GameStatus(game)
+	for _, allowed := range gameOperations[status] {
+		if allowed == operation {
+			return nil
+		}
+	}
+	switch {
+	case status == dataaccess.StatusDrawn:
+		return gerr.ErrGameAlreadyDrawn
+	case operation == OperationRepairDraw || operation == OperationReset:
+		return gerr.ErrGameNotDrawn
+	case operation == OperationRestore:
+		return gerr.ErrGameNotReset
+	case operation == OperationDraw:
+		return gerr.ErrGameNotReady
+	}
+	return gerr.ErrInvalidStatusTransition
+}
+
+// CheckEventDate tells whether the event date of a game may be changed. The audit of a draw is revealed after
+// its event date, so a new date has to lie in the future in every status, and once drawn it may only be moved later.
+// Keeping the current date is always allowed.
+func CheckEventDate(game *dataaccess.Game, eventDate time.Time, now time.Time) error {
+
+	if game.EventDate != nil && eventDate.Equal(*game.EventDate) {
+		return nil
+	}
+	if GameStatus(game) == dataaccess.StatusDrawn && game.EventDate != nil && eventDate.Before(*game.EventDate) {
+		return gerr.ErrEventDateMovedEarlier
+	}
+	if !eventDate.After(now) {
+		return gerr.ErrEventDatePassed
+	}
+	return nil
+}
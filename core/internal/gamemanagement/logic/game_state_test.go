@@ -0,0 +1,209 @@
+package logic_test
+
+import (
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	da "github.com/yoktobit/secretsanta/internal/gamemanagement/dataaccess"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/errors"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+)
+
+var _ = Describe("GameState", func() {
+
+	statuses := []da.Status{da.StatusCreated, da.StatusWaiting, da.StatusReady, da.StatusDrawn, da.StatusReset}
+	allowedTransitions := map[da.Status][]da.Status{
+		da.StatusCreated: {da.StatusWaiting, da.StatusReady},
+		da.StatusWaiting: {da.StatusReady},
+		da.StatusReady:   {da.StatusWaiting, da.StatusDrawn},
+		da.StatusDrawn:   {da.StatusReset},
+		da.StatusReset:   {da.StatusWaiting, da.StatusDrawn},
+	}
+
+	Context("Transitions", func() {
+		for _, from := range statuses {
+			for _, to := range statuses {
+				from, to := from, to
+				allowed := from == to
+				for _, status := range allowedTransitions[from] {
+					if status == to {
+						allowed = true
+					}
+				}
+				if allowed {
+					It("should change a game from "+from.String()+" to "+to.String(), func() {
+						game := da.Game{Status: from.String()}
+						Expect(logic.TransitionGame(&game, to)).To(Succeed())
+						Expect(game.Status).To(Equal(to.String()))
+					})
+				} else {
+					It("should not change a game from "+from.String()+" to "+to.String(), func() {
+						game := da.Game{Status: from.String()}
+						Expect(logic.TransitionGame(&game, to)).To(MatchError(errors.ErrInvalidStatusTransition))
+						Expect(game.Status).To(Equal(from.String()))
+					})
+				}
+			}
+		}
+		It("should treat a game without status as created", func() {
+			game := da.Game{}
+			Expect(logic.GameStatus(&game)).To(Equal(da.StatusCreated))
+			Expect(logic.TransitionGame(&game, da.StatusDrawn)).To(MatchError(errors.ErrInvalidStatusTransition))
+		})
+	})
+
+	Context("Operations", func() {
+		editable := []da.Status{da.StatusCreated, da.StatusWaiting, da.StatusReady, da.StatusReset}
+		for _, status := range editable {
+			status := status
+			It("should allow to edit a "+status.String()+" game", func() {
+				game := da.Game{Status: status.String()}
+				Expect(logic.CheckGameOperation(&game, logic.OperationEditPlayers)).To(Succeed())
+				Expect(logic.CheckGameOperation(&game, logic.OperationUpdateSettings)).To(Succeed())
+				Expect(logic.CheckGameOperation(&game, logic.OperationUpdateDetails)).To(Succeed())
+				Expect(logic.CheckGameOperation(&game, logic.OperationRegisterPlayer)).To(Succeed())
+				Expect(logic.CheckGameOperation(&game, logic.OperationRepairDraw)).To(MatchError(errors.ErrGameNotDrawn))
+				Expect(logic.CheckGameOperation(&game, logic.OperationReset)).To(MatchError(errors.ErrGameNotDrawn))
+			})
+		}
+		It("should only draw a ready or reset game", func() {
+			Expect(logic.CheckGameOperation(&da.Game{Status: da.StatusCreated.String()}, logic.OperationDraw)).To(MatchError(errors.ErrGameNotReady))
+			Expect(logic.CheckGameOperation(&da.Game{Status: da.StatusWaiting.String()}, logic.OperationDraw)).To(MatchError(errors.ErrGameNotReady))
+			Expect(logic.CheckGameOperation(&da.Game{Status: da.StatusReady.String()}, logic.OperationDraw)).To(Succeed())
+			Expect(logic.CheckGameOperation(&da.Game{Status: da.StatusReset.String()}, logic.OperationDraw)).To(Succeed())
+			Expect(logic.CheckGameOperation(&da.Game{Status: da.StatusDrawn.String()}, logic.OperationDraw)).To(MatchError(errors.ErrGameAlreadyDrawn))
+		})
+		It("should only restore a reset game", func() {
+			Expect(logic.CheckGameOperation(&da.Game{Status: da.StatusReset.String()}, logic.OperationRestore)).To(Succeed())
+			Expect(logic.CheckGameOperation(&da.Game{Status: da.StatusCreated.String()}, logic.OperationRestore)).To(MatchError(errors.ErrGameNotReset))
+			Expect(logic.CheckGameOperation(&da.Game{Status: da.StatusWaiting.String()}, logic.OperationRestore)).To(MatchError(errors.ErrGameNotReset))
+			Expect(logic.CheckGameOperation(&da.Game{Status: da.StatusReady.String()}, logic.OperationRestore)).To(MatchError(errors.ErrGameNotReset))
+			Expect(logic.CheckGameOperation(&da.Game{Status: da.StatusDrawn.String()}, logic.OperationRestore)).To(MatchError(errors.ErrGameAlreadyDrawn))
+		})
+		It("should only repair or reset a drawn game", func() {
+			game := da.Game{Status: da.StatusDrawn.String()}
+			Expect(logic.CheckGameOperation(&game, logic.OperationRepairDraw)).To(Succeed())
+			Expect(logic.CheckGameOperation(&game, logic.OperationReset)).To(Succeed())
+			Expect(logic.CheckGameOperation(&game, logic.OperationRegisterPlayer)).To(Succeed())
+			Expect(logic.CheckGameOperation(&game, logic.OperationUpdateDetails)).To(Succeed())
+			Expect(logic.CheckGameOperation(&game, logic.OperationEditPlayers)).To(MatchError(errors.ErrGameAlreadyDrawn))
+			Expect(logic.CheckGameOperation(&game, logic.OperationUpdateSettings)).To(MatchError(errors.ErrGameAlreadyDrawn))
+		})
+	})
+
+	Context("Gamemanagement", func() {
+		var gamemanagement logic.Gamemanagement
+		var mock sqlmock.Sqlmock
+		code := "ABC"
+
+		BeforeEach(func() {
+			var c dataaccess.Connection
+			c, mock = dataaccess.NewMockConnection()
+			gamemanagement = newTestGamemanagement(c, nil, nil)
+		})
+		expectGame := func(status string) {
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, status))
+		}
+
+		It("should not draw a waiting game", func() {
+			expectGame("Waiting")
+			_, err := gamemanagement.DrawGame(to.DrawGameTo{GameCode: code})
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrGameNotReady))
+		})
+		It("should not draw a drawn game again", func() {
+			expectGame("Drawn")
+			_, err := gamemanagement.DrawGame(to.DrawGameTo{GameCode: code})
+			Expect(err).To(MatchError(errors.ErrGameAlreadyDrawn))
+		})
+		It("should not reset a game which has not been drawn", func() {
+			expectGame("Ready")
+			err := gamemanagement.ResetGame(code)
+			Expect(err).To(MatchError(errors.ErrGameNotDrawn))
+		})
+		It("should not change the settings of a drawn game", func() {
+			forbidMutualPairs := true
+			expectGame("Drawn")
+			err := gamemanagement.UpdateGameSettings(to.UpdateGameSettingsTo{GameCode: code, ForbidMutualPairs: &forbidMutualPairs})
+			Expect(err).To(MatchError(errors.ErrGameAlreadyDrawn))
+		})
+		It("should only move the event date of a drawn game later", func() {
+			now := time.Now()
+			eventDate := now.Add(24 * time.Hour)
+			game := da.Game{Status: "Drawn", EventDate: &eventDate}
+			Expect(logic.CheckEventDate(&game, eventDate.Add(time.Hour), now)).To(Succeed())
+			Expect(logic.CheckEventDate(&game, eventDate.Add(-time.Hour), now)).To(MatchError(errors.ErrEventDateMovedEarlier))
+			Expect(logic.CheckEventDate(&game, now.Add(-time.Hour), now)).To(MatchError(errors.ErrEventDateMovedEarlier))
+		})
+		It("should only set the missing event date of a drawn game in the future", func() {
+			now := time.Now()
+			game := da.Game{Status: "Drawn"}
+			Expect(logic.CheckEventDate(&game, now.Add(time.Hour), now)).To(Succeed())
+			Expect(logic.CheckEventDate(&game, now.Add(-time.Hour), now)).To(MatchError(errors.ErrEventDatePassed))
+		})
+		It("should move the event date of a game which has not been drawn freely within the future", func() {
+			now := time.Now()
+			eventDate := now.Add(24 * time.Hour)
+			game := da.Game{Status: "Ready", EventDate: &eventDate}
+			Expect(logic.CheckEventDate(&game, now.Add(time.Hour), now)).To(Succeed())
+			Expect(logic.CheckEventDate(&game, eventDate, now.Add(48*time.Hour))).To(Succeed())
+		})
+		It("should not set the event date of a game in any status into the past", func() {
+			now := time.Now()
+			eventDate := now.Add(24 * time.Hour)
+			for _, status := range []string{"", "Waiting", "Ready", "Reset"} {
+				game := da.Game{Status: status, EventDate: &eventDate}
+				Expect(logic.CheckEventDate(&game, now.Add(-time.Hour), now)).To(MatchError(errors.ErrEventDatePassed))
+				Expect(logic.CheckEventDate(&game, now, now)).To(MatchError(errors.ErrEventDatePassed))
+			}
+		})
+		It("should not reveal the audit of a drawn game by moving the event date into the past", func() {
+			eventDate := time.Now().Add(-time.Hour)
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "event_date"}).AddRow(1, code, "Drawn", time.Now().Add(time.Hour)))
+			err := gamemanagement.UpdateGameSettings(to.UpdateGameSettingsTo{GameCode: code, EventDate: &eventDate})
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrEventDateMovedEarlier))
+		})
+		It("should not add exceptions to a drawn game", func() {
+			expectGame("Drawn")
+			_, err := gamemanagement.AddException(to.AddExceptionTo{NameA: "Max", NameB: "Moritz", GameCode: code})
+			Expect(err).To(MatchError(errors.ErrGameAlreadyDrawn))
+		})
+		It("should let a player added to a reset game wait for the registration", func() {
+			expectGame("Reset")
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(1, "Max", "Max"))
+			mock.ExpectBegin()
+			mock.ExpectQuery(`INSERT INTO "players"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+			mock.ExpectExec(`DELETE FROM "invites" WHERE player_id = \$1`).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`INSERT INTO "invites"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", code, "Waiting", "", false, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			_, err := gamemanagement.AddPlayerToGame(to.AddRemovePlayerTo{Name: "Strolch", GameCode: code})
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should keep a reset game reset when a player registers", func() {
+			expectGame("Reset")
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "role", "game_id"}).AddRow(1, "Max", "Player", 1))
+			mock.ExpectBegin()
+			mock.ExpectExec(`DELETE FROM "invites"`).WithArgs(sqlmock.AnyArg(), 1, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", "Max", "max", sqlmock.AnyArg(), 1, "Ready", "Player", nil, false, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(`DELETE FROM "invites" WHERE player_id = \$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := gamemanagement.RegisterPlayerPassword(to.RegisterLoginPlayerPasswordTo{Name: "Max", Password: "Pass", GameCode: code, InviteToken: "Token"})
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should not change the status when the players are listed", func() {
+			expectGame("Waiting")
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status"}).AddRow(1, "Max", "Ready"))
+			_, err := gamemanagement.GetPlayersByCode(code)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
@@ -1,7 +1,13 @@
 package logic
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/lithammer/shortuuid"
@@ -11,6 +17,7 @@ import (
 	gda "github.com/yoktobit/secretsanta/internal/general/dataaccess"
 	glogic "github.com/yoktobit/secretsanta/internal/general/logic"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -19,33 +26,65 @@ import (
 type Gamemanagement interface {
 	Connection() gda.Connection
 	CreateNewGame(createGameTo to.CreateGameTo) (to.CreateGameResponseTo, error)
-	AddPlayerToGame(addPlayerTo to.AddRemovePlayerTo) error
+	UpdateGameSettings(updateGameSettingsTo to.UpdateGameSettingsTo) error
+	AddPlayerToGame(addPlayerTo to.AddRemovePlayerTo) (to.InviteTo, error)
 	RemovePlayerFromGame(removePlayerTo to.AddRemovePlayerTo) error
+	RemovePlayerFromDrawnGame(dropOutPlayerTo to.DropOutPlayerTo) (to.RepairDrawResponseTo, error)
+	AddPlayerToDrawnGame(lateJoinPlayerTo to.LateJoinPlayerTo) (to.RepairDrawResponseTo, error)
+	RenamePlayer(renamePlayerTo to.RenamePlayerTo) error
+	PromotePlayer(changeRoleTo to.ChangeRoleTo) error
+	DemotePlayer(changeRoleTo to.ChangeRoleTo) error
+	TransferAdmin(transferAdminTo to.TransferAdminTo) error
+	IssueInvite(invitePlayerTo to.InvitePlayerTo) (to.InviteTo, error)
+	RevokeInvite(invitePlayerTo to.InvitePlayerTo) error
 	RegisterPlayerPassword(registerPlayerPasswordTo to.RegisterLoginPlayerPasswordTo) error
-	AddException(addExceptionTo to.AddExceptionTo) error
+	AddException(addExceptionTo to.AddExceptionTo) (to.FeasibilityResponseTo, error)
+	RemoveException(removeExceptionTo to.RemoveExceptionTo) error
+	ReplaceExceptions(replaceExceptionsTo to.ReplaceExceptionsTo) (to.FeasibilityResponseTo, error)
+	CheckFeasibility(code string) (to.FeasibilityResponseTo, error)
 	GetBasicGameByCode(code string) (to.GetBasicGameResponseTo, error)
 	GetFullGameByCode(code string, playerName string) (to.GetFullGameResponseTo, error)
 	GetPlayersByCode(code string) ([]to.PlayerResponseTo, error)
 	GetPlayerRoleByCodeAndName(code string, name string) (string, error)
 	GetExceptionsByCode(code string) ([]to.ExceptionResponseTo, error)
-	LoginPlayer(loginPlayerPasswordTo to.RegisterLoginPlayerPasswordTo) to.RegisterLoginPlayerPasswordResponseTo
+	AddHousehold(addHouseholdTo to.AddRemoveHouseholdTo) error
+	RemoveHousehold(removeHouseholdTo to.AddRemoveHouseholdTo) error
+	AssignPlayerToHousehold(assignHouseholdTo to.AssignHouseholdTo) (to.FeasibilityResponseTo, error)
+	GetHouseholdsByCode(code string) ([]to.HouseholdResponseTo, error)
+	AddPreference(addPreferenceTo to.AddPreferenceTo) error
+	RemovePreference(removePreferenceTo to.RemovePreferenceTo) error
+	GetPreferencesByCode(code string) ([]to.PreferenceResponseTo, error)
+	GetDrawAudit(code string) (to.DrawAuditTo, error)
+	VerifyDraw(drawAuditTo to.DrawAuditTo) error
+	LoginPlayer(loginPlayerPasswordTo to.RegisterLoginPlayerPasswordTo, clientIP string) to.RegisterLoginPlayerPasswordResponseTo
 	DrawGame(drawGameTo to.DrawGameTo) (to.DrawGameResponseTo, error)
+	PreviewDraw(code string) (to.DrawPreviewTo, error)
 	ResetGame(gameCode string) error
+	RestoreGame(gameCode string) error
 }
 
 type gamemanagement struct {
-	connection                gda.Connection
-	gameRepository            dataaccess.GameRepository
-	playerRepository          dataaccess.PlayerRepository
-	playerExceptionRepository dataaccess.PlayerExceptionRepository
-	random                    glogic.Randomizer
+	connection                 gda.Connection
+	gameRepository             dataaccess.GameRepository
+	playerRepository           dataaccess.PlayerRepository
+	playerExceptionRepository  dataaccess.PlayerExceptionRepository
+	householdRepository        dataaccess.HouseholdRepository
+	assignmentRepository       dataaccess.AssignmentRepository
+	playerPreferenceRepository dataaccess.PlayerPreferenceRepository
+	drawAuditRepository        dataaccess.DrawAuditRepository
+	drawRoundRepository        dataaccess.DrawRoundRepository
+	inviteRepository           dataaccess.InviteRepository
+	loginAuditRepository       dataaccess.LoginAuditRepository
+	loginAttemptStore          LoginAttemptStore
+	drawStrategies             *DrawStrategies
+	random                     glogic.Randomizer
 }
 
 // NewGamemanagement is the factory method to create a new Gamemanagement
-func NewGamemanagement(connection gda.Connection, gameRepository dataaccess.GameRepository, playerRepository dataaccess.PlayerRepository, playerExceptionRepository dataaccess.PlayerExceptionRepository, random glogic.Randomizer) Gamemanagement {
+func NewGamemanagement(connection gda.Connection, gameRepository dataaccess.GameRepository, playerRepository dataaccess.PlayerRepository, playerExceptionRepository dataaccess.PlayerExceptionRepository, householdRepository dataaccess.HouseholdRepository, assignmentRepository dataaccess.AssignmentRepository, playerPreferenceRepository dataaccess.PlayerPreferenceRepository, drawAuditRepository dataaccess.DrawAuditRepository, drawRoundRepository dataaccess.DrawRoundRepository, inviteRepository dataaccess.InviteRepository, loginAuditRepository dataaccess.LoginAuditRepository, loginAttemptStore LoginAttemptStore, drawStrategies *DrawStrategies, random glogic.Randomizer) Gamemanagement {
 
 	dataaccess.MigrateDb(connection.Connection())
-	return &gamemanagement{connection: connection, gameRepository: gameRepository, playerRepository: playerRepository, playerExceptionRepository: playerExceptionRepository, random: random}
+	return &gamemanagement{connection: connection, gameRepository: gameRepository, playerRepository: playerRepository, playerExceptionRepository: playerExceptionRepository, householdRepository: householdRepository, assignmentRepository: assignmentRepository, playerPreferenceRepository: playerPreferenceRepository, drawAuditRepository: drawAuditRepository, drawRoundRepository: drawRoundRepository, inviteRepository: inviteRepository, loginAuditRepository: loginAuditRepository, loginAttemptStore: loginAttemptStore, drawStrategies: drawStrategies, random: random}
 }
 
 // Connection returns the database connection
@@ -61,12 +100,42 @@ func (gamemanagement *gamemanagement) CreateNewGame(createGameTo to.CreateGameTo
 		return to.CreateGameResponseTo{}, err
 	}
 	code := gamemanagement.generateCode()
-	game := dataaccess.Game{Code: code, Title: createGameTo.Title, Description: createGameTo.Description, Status: dataaccess.StatusCreated.String()}
+	drawMode := createGameTo.DrawMode
+	if drawMode == "" {
+		drawMode = dataaccess.DrawModeFree.String()
+	}
+	giftsPerPlayer := createGameTo.GiftsPerPlayer
+	if giftsPerPlayer == 0 {
+		giftsPerPlayer = 1
+	}
+	if _, err := gamemanagement.drawStrategies.Drawer(createGameTo.DrawStrategy); err != nil {
+		return to.CreateGameResponseTo{}, err
+	}
+	if createGameTo.EventDate != nil {
+		// the new game has no event date yet, so the date is checked as if it was set for the first time
+		err = CheckEventDate(&dataaccess.Game{}, *createGameTo.EventDate, time.Now())
+		if err != nil {
+			return to.CreateGameResponseTo{}, err
+		}
+	}
+	game := dataaccess.Game{Code: code, Title: createGameTo.Title, Description: createGameTo.Description, Status: dataaccess.StatusCreated.String(), DrawMode: drawMode, ForbidMutualPairs: createGameTo.ForbidMutualPairs, AvoidRepeatYears: createGameTo.AvoidRepeatYears, GiftsPerPlayer: giftsPerPlayer, EventDate: createGameTo.EventDate, DrawStrategy: createGameTo.DrawStrategy, Budget: createGameTo.Budget}
+
+	if createGameTo.PreviousGameCode != "" {
+		previousGame, err := gamemanagement.findPreviousGame(&game, createGameTo.PreviousGameCode, createGameTo.AdminUser, createGameTo.PreviousGamePassword)
+		if err != nil {
+			return to.CreateGameResponseTo{}, err
+		}
+		game.PreviousGameID = &previousGame.ID
+	}
 	hashedPassword := gamemanagement.generatePassword(createGameTo.AdminPassword)
+	role := dataaccess.RoleAdmin
+	if createGameTo.OrganizerOnly {
+		role = dataaccess.RoleOrganizer
+	}
 	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
 		// hier Code ausgeben
 		gamemanagement.gameRepository.CreateGame(c, &game)
-		player := dataaccess.Player{Name: createGameTo.AdminUser, Password: hashedPassword, GameID: game.ID, Role: dataaccess.RoleAdmin.String(), Status: dataaccess.StatusReady.String()}
+		player := dataaccess.Player{Name: createGameTo.AdminUser, Password: hashedPassword, GameID: game.ID, Role: role.String(), Status: dataaccess.StatusReady.String()}
 		gamemanagement.playerRepository.CreatePlayer(c, &player)
 		return nil
 	})
@@ -74,24 +143,121 @@ func (gamemanagement *gamemanagement) CreateNewGame(createGameTo to.CreateGameTo
 	return result, nil
 }
 
-// AddPlayerToGame adds a new player to an existing game
-func (gamemanagement *gamemanagement) AddPlayerToGame(addPlayerTo to.AddRemovePlayerTo) error {
-	err := validator.New().Struct(addPlayerTo)
+// UpdateGameSettings changes the settings of an existing game
+func (gamemanagement *gamemanagement) UpdateGameSettings(updateGameSettingsTo to.UpdateGameSettingsTo) error {
+	err := validator.New().Struct(updateGameSettingsTo)
 	if err != nil {
 		return err
 	}
-	game, err := gamemanagement.gameRepository.FindGameByCode(addPlayerTo.GameCode)
+	game, err := gamemanagement.gameRepository.FindGameByCode(updateGameSettingsTo.GameCode)
+	if err != nil {
+		return err
+	}
+	err = CheckGameOperation(&game, OperationUpdateDetails)
 	if err != nil {
 		return err
 	}
+	if changesDrawOptions(updateGameSettingsTo) {
+		err = CheckGameOperation(&game, OperationUpdateSettings)
+		if err != nil {
+			return err
+		}
+	}
+	if updateGameSettingsTo.Title != nil {
+		game.Title = *updateGameSettingsTo.Title
+	}
+	if updateGameSettingsTo.Description != nil {
+		game.Description = *updateGameSettingsTo.Description
+	}
+	if updateGameSettingsTo.Budget != nil {
+		game.Budget = *updateGameSettingsTo.Budget
+	}
+	if updateGameSettingsTo.DrawMode != nil {
+		game.DrawMode = *updateGameSettingsTo.DrawMode
+	}
+	if updateGameSettingsTo.ForbidMutualPairs != nil {
+		game.ForbidMutualPairs = *updateGameSettingsTo.ForbidMutualPairs
+	}
+	if updateGameSettingsTo.PreviousGameCode != nil {
+		game.PreviousGameID = nil
+		if *updateGameSettingsTo.PreviousGameCode != "" {
+			previousGame, err := gamemanagement.findPreviousGame(&game, *updateGameSettingsTo.PreviousGameCode, updateGameSettingsTo.PlayerName, updateGameSettingsTo.PreviousGamePassword)
+			if err != nil {
+				return err
+			}
+			game.PreviousGameID = &previousGame.ID
+		}
+	}
+	if updateGameSettingsTo.AvoidRepeatYears != nil {
+		game.AvoidRepeatYears = *updateGameSettingsTo.AvoidRepeatYears
+	}
+	if updateGameSettingsTo.GiftsPerPlayer != nil {
+		game.GiftsPerPlayer = *updateGameSettingsTo.GiftsPerPlayer
+	}
+	if updateGameSettingsTo.EventDate != nil {
+		err = CheckEventDate(&game, *updateGameSettingsTo.EventDate, time.Now())
+		if err != nil {
+			return err
+		}
+		game.EventDate = updateGameSettingsTo.EventDate
+	}
+	if updateGameSettingsTo.DrawStrategy != nil {
+		if _, err := gamemanagement.drawStrategies.Drawer(*updateGameSettingsTo.DrawStrategy); err != nil {
+			return err
+		}
+		game.DrawStrategy = *updateGameSettingsTo.DrawStrategy
+	}
+	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		gamemanagement.gameRepository.UpdateGame(c, &game)
+		return nil
+	})
+	return nil
+}
+
+// changesDrawOptions tells whether a settings update changes how the game is drawn
+func changesDrawOptions(updateGameSettingsTo to.UpdateGameSettingsTo) bool {
+
+	return updateGameSettingsTo.DrawMode != nil || updateGameSettingsTo.ForbidMutualPairs != nil || updateGameSettingsTo.PreviousGameCode != nil || updateGameSettingsTo.AvoidRepeatYears != nil || updateGameSettingsTo.GiftsPerPlayer != nil || updateGameSettingsTo.DrawStrategy != nil
+}
+
+// AddPlayerToGame adds a new player to an existing game and invites him/her to register
+func (gamemanagement *gamemanagement) AddPlayerToGame(addPlayerTo to.AddRemovePlayerTo) (to.InviteTo, error) {
+	err := validator.New().Struct(addPlayerTo)
+	if err != nil {
+		return to.InviteTo{}, err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(addPlayerTo.GameCode)
+	if err != nil {
+		return to.InviteTo{}, err
+	}
+	err = CheckGameOperation(&game, OperationEditPlayers)
+	if err != nil {
+		return to.InviteTo{}, err
+	}
+	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
+	if err != nil {
+		return to.InviteTo{}, err
+	}
+	if nameTaken(players, addPlayerTo.Name) {
+		return to.InviteTo{}, gerr.ErrPlayerAlreadyExists
+	}
 	player := dataaccess.Player{Name: addPlayerTo.Name, GameID: game.ID, Role: dataaccess.RolePlayer.String()}
-	game.Status = dataaccess.StatusWaiting.String()
+	err = TransitionGame(&game, dataaccess.StatusWaiting)
+	if err != nil {
+		return to.InviteTo{}, err
+	}
+	token, err := newInviteToken()
+	if err != nil {
+		return to.InviteTo{}, err
+	}
+	var inviteTo to.InviteTo
 	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
 		gamemanagement.playerRepository.CreatePlayer(c, &player)
+		inviteTo = gamemanagement.createInvite(c, &player, token)
 		gamemanagement.gameRepository.UpdateGame(c, &game)
 		return nil
 	})
-	return nil
+	return inviteTo, nil
 }
 
 // RemovePlayerFromGame removes an existing player from an existing game
@@ -104,207 +270,1072 @@ func (gamemanagement *gamemanagement) RemovePlayerFromGame(removePlayerTo to.Add
 	if err != nil {
 		return err
 	}
+	err = CheckGameOperation(&game, OperationEditPlayers)
+	if err != nil {
+		return err
+	}
 	player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(removePlayerTo.Name, game.ID)
-	if err == nil {
-		gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
-			gamemanagement.playerExceptionRepository.DeleteExceptionByPlayerID(c, player.ID)
-			gamemanagement.playerRepository.DeletePlayerByNameAndGameID(c, removePlayerTo.Name, game.ID)
-			gamemanagement.refreshGameStatus(c, &game)
-			return nil
-		})
+	if err != nil {
+		return err
 	}
-	return err
+	return gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		err := gamemanagement.checkKeepsAdmin(c, &player)
+		if err != nil {
+			return err
+		}
+		gamemanagement.playerExceptionRepository.DeleteExceptionByPlayerID(c, player.ID)
+		gamemanagement.playerPreferenceRepository.DeletePreferenceByPlayerID(c, player.ID)
+		gamemanagement.playerRepository.DeletePlayerByNameAndGameID(c, removePlayerTo.Name, game.ID)
+		gamemanagement.refreshGameStatus(c, &game)
+		return nil
+	})
 }
 
-// RegisterPlayerPassword registers the password for a player and tells he/she is ready to go
-func (gamemanagement *gamemanagement) RegisterPlayerPassword(registerPlayerPasswordTo to.RegisterLoginPlayerPasswordTo) error {
-	err := validator.New().Struct(registerPlayerPasswordTo)
+// RemovePlayerFromDrawnGame removes a player who drops out after the draw. Only the players who gifted him/her
+// get new receivers. The lots of all others stay unchanged, unless the exceptions leave no other way and the
+// organizer allowed other players to exchange their receivers.
+func (gamemanagement *gamemanagement) RemovePlayerFromDrawnGame(dropOutPlayerTo to.DropOutPlayerTo) (to.RepairDrawResponseTo, error) {
+	err := validator.New().Struct(dropOutPlayerTo)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(dropOutPlayerTo.GameCode)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	err = CheckGameOperation(&game, OperationRepairDraw)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(dropOutPlayerTo.Name, game.ID)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	context, err := gamemanagement.loadDrawContext(&game)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	assignments, err := gamemanagement.assignmentRepository.FindAssignmentsByGameID(game.ID)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	remaining := make([]*dataaccess.Player, 0, len(context.Players))
+	for _, other := range context.Players {
+		if other.ID != player.ID {
+			remaining = append(remaining, other)
+		}
+	}
+	context.Players = remaining
+	input := gamemanagement.drawStrategies.Input(context)
+	index := playerIndices(remaining)
+	assignment := make([][]int, len(remaining))
+	openGivers := make([]int, 0)
+	openReceivers := make([]int, 0)
+	for _, pair := range assignments {
+		switch {
+		case pair.GiverID == player.ID:
+			openReceivers = append(openReceivers, index[pair.ReceiverID])
+		case pair.ReceiverID == player.ID:
+			openGivers = append(openGivers, index[pair.GiverID])
+		default:
+			assignment[index[pair.GiverID]] = append(assignment[index[pair.GiverID]], index[pair.ReceiverID])
+		}
+	}
+	maxExchanges := 0
+	if dropOutPlayerTo.ExchangeOthers {
+		maxExchanges = maxRepairExchanges
+	}
+	repaired, changed, ok := repairAssignment(input, assignment, openGivers, openReceivers, maxExchanges, gamemanagement.random)
+	if !ok && !dropOutPlayerTo.ExchangeOthers {
+		if _, _, exchangeable := repairAssignment(input, assignment, openGivers, openReceivers, maxRepairExchanges, gamemanagement.random); exchangeable {
+			return to.RepairDrawResponseTo{Ok: false, Message: "Mit den definierten Ausnahmen müssen auch andere Personen ihre Beschenkten tauschen. Bitte erlaube den Tausch oder lose neu aus."}, nil
+		}
+	}
+	if !ok {
+		return to.RepairDrawResponseTo{Ok: false, Message: "Ohne die Person ist mit den definierten Ausnahmen keine Auslosung möglich, die die übrigen Lose erhält. Bitte neu auslosen."}, nil
+	}
+	drawAudit, err := gamemanagement.auditRepair(&game, drawRepairDropOut, player.Name, remaining, repaired)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	err = gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		err := gamemanagement.checkKeepsAdmin(c, &player)
+		if err != nil {
+			return err
+		}
+		gamemanagement.assignmentRepository.DeleteAssignmentsByPlayerID(c, player.ID)
+		for _, giver := range changed {
+			gamemanagement.assignmentRepository.DeleteAssignmentsByGiverID(c, remaining[giver].ID)
+			for _, receiver := range repaired[giver] {
+				newAssignment := dataaccess.Assignment{GameID: game.ID, GiverID: remaining[giver].ID, ReceiverID: remaining[receiver].ID}
+				gamemanagement.assignmentRepository.CreateAssignment(c, &newAssignment)
+			}
+			gamemanagement.playerRepository.MarkReceiversChangedByID(c, remaining[giver].ID)
+		}
+		gamemanagement.playerExceptionRepository.DeleteExceptionByPlayerID(c, player.ID)
+		gamemanagement.playerPreferenceRepository.DeletePreferenceByPlayerID(c, player.ID)
+		gamemanagement.playerRepository.DeletePlayerByNameAndGameID(c, player.Name, game.ID)
+		if drawAudit != nil {
+			gamemanagement.drawAuditRepository.UpdateDrawAudit(c, drawAudit)
+		}
+		return nil
+	})
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	return to.RepairDrawResponseTo{Ok: true, Changed: len(changed)}, nil
+}
+
+// AddPlayerToDrawnGame adds a player who joins after the draw. The new player is spliced into the existing lots,
+// so only as many players get other receivers as the new player has to gift.
+func (gamemanagement *gamemanagement) AddPlayerToDrawnGame(lateJoinPlayerTo to.LateJoinPlayerTo) (to.RepairDrawResponseTo, error) {
+	err := validator.New().Struct(lateJoinPlayerTo)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(lateJoinPlayerTo.GameCode)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	err = CheckGameOperation(&game, OperationRepairDraw)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	// the draw context leaves out the organizers, so the name is looked up among all players
+	allPlayers, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	if nameTaken(allPlayers, lateJoinPlayerTo.Name) {
+		return to.RepairDrawResponseTo{}, gerr.ErrPlayerAlreadyExists
+	}
+	context, err := gamemanagement.loadDrawContext(&game)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	byName := make(map[string]*dataaccess.Player)
+	for _, other := range context.Players {
+		byName[dataaccess.NormalizeName(other.Name)] = other
+	}
+	// the new player is not saved before the lots are found, no saved player has the ID 0
+	player := dataaccess.Player{Name: lateJoinPlayerTo.Name, GameID: game.ID, Role: dataaccess.RolePlayer.String()}
+	newExceptions := make([]*dataaccess.PlayerException, 0, len(lateJoinPlayerTo.NotGifting)+len(lateJoinPlayerTo.NotGiftedBy))
+	for _, name := range lateJoinPlayerTo.NotGifting {
+		other, found := byName[dataaccess.NormalizeName(name)]
+		if !found {
+			return to.RepairDrawResponseTo{}, gerr.ErrPlayerNotFound
+		}
+		newExceptions = append(newExceptions, &dataaccess.PlayerException{PlayerA: player, PlayerB: *other, GameID: game.ID})
+	}
+	for _, name := range lateJoinPlayerTo.NotGiftedBy {
+		other, found := byName[dataaccess.NormalizeName(name)]
+		if !found {
+			return to.RepairDrawResponseTo{}, gerr.ErrPlayerNotFound
+		}
+		newExceptions = append(newExceptions, &dataaccess.PlayerException{PlayerA: *other, PlayerB: player, GameID: game.ID})
+	}
+	assignments, err := gamemanagement.assignmentRepository.FindAssignmentsByGameID(game.ID)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	players := append(context.Players, &player)
+	context.Players = players
+	context.Exceptions = append(context.Exceptions, newExceptions...)
+	input := gamemanagement.drawStrategies.Input(context)
+	index := playerIndices(players)
+	newPlayer := len(players) - 1
+	assignment := make([][]int, len(players))
+	for _, pair := range assignments {
+		assignment[index[pair.GiverID]] = append(assignment[index[pair.GiverID]], index[pair.ReceiverID])
+	}
+	spliced, changed, ok := spliceAssignment(input, assignment, newPlayer, gamemanagement.random)
+	if !ok {
+		return to.RepairDrawResponseTo{Ok: false, Message: "Mit den definierten Ausnahmen kann die Person nicht in die bestehenden Lose aufgenommen werden. Bitte neu auslosen."}, nil
+	}
+	token, err := newInviteToken()
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	drawAudit, err := gamemanagement.auditRepair(&game, drawRepairLateJoin, player.Name, players, spliced)
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	repairDrawResponseTo := to.RepairDrawResponseTo{Ok: true, Changed: len(changed)}
+	err = gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		gamemanagement.playerRepository.CreatePlayer(c, &player)
+		inviteTo := gamemanagement.createInvite(c, &player, token)
+		repairDrawResponseTo.Invite = &inviteTo
+		for _, playerException := range newExceptions {
+			if playerException.PlayerA.ID == 0 {
+				playerException.PlayerA = player
+			} else {
+				playerException.PlayerB = player
+			}
+			gamemanagement.playerExceptionRepository.CreatePlayerException(c, playerException)
+		}
+		for _, giver := range changed {
+			gamemanagement.assignmentRepository.DeleteAssignmentsByGiverID(c, players[giver].ID)
+			gamemanagement.playerRepository.MarkReceiversChangedByID(c, players[giver].ID)
+		}
+		for _, giver := range append(changed, newPlayer) {
+			for _, receiver := range spliced[giver] {
+				newAssignment := dataaccess.Assignment{GameID: game.ID, GiverID: players[giver].ID, ReceiverID: players[receiver].ID}
+				gamemanagement.assignmentRepository.CreateAssignment(c, &newAssignment)
+			}
+		}
+		if drawAudit != nil {
+			gamemanagement.drawAuditRepository.UpdateDrawAudit(c, drawAudit)
+		}
+		return nil
+	})
+	if err != nil {
+		return to.RepairDrawResponseTo{}, err
+	}
+	return repairDrawResponseTo, nil
+}
+
+// RenamePlayer changes the name shown to the other players. The login name stays the same and the exceptions,
+// households and lots only refer to the player, so they are kept in every status of the game.
+func (gamemanagement *gamemanagement) RenamePlayer(renamePlayerTo to.RenamePlayerTo) error {
+	// a name of only spaces is no name, so it is trimmed before it is validated
+	renamePlayerTo.DisplayName = strings.TrimSpace(renamePlayerTo.DisplayName)
+	err := validator.New().Struct(renamePlayerTo)
 	if err != nil {
 		return err
 	}
-	game, err := gamemanagement.gameRepository.FindGameByCode(registerPlayerPasswordTo.GameCode)
+	game, err := gamemanagement.gameRepository.FindGameByCode(renamePlayerTo.GameCode)
 	if err != nil {
 		return err
 	}
-	player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(registerPlayerPasswordTo.Name, game.ID)
+	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
 	if err != nil {
 		return err
 	}
-	player.Password = gamemanagement.generatePassword(registerPlayerPasswordTo.Password)
-	player.Status = dataaccess.StatusReady.String()
+	var player *dataaccess.Player
+	others := make([]*dataaccess.Player, 0, len(players))
+	nameKey := dataaccess.NormalizeName(renamePlayerTo.Name)
+	for _, other := range players {
+		if dataaccess.NormalizeName(other.Name) == nameKey {
+			player = other
+		} else {
+			others = append(others, other)
+		}
+	}
+	if player == nil {
+		return gerr.ErrPlayerNotFound
+	}
+	if nameTaken(others, renamePlayerTo.DisplayName) {
+		return gerr.ErrPlayerAlreadyExists
+	}
+	player.DisplayName = renamePlayerTo.DisplayName
 	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
-		gamemanagement.playerRepository.UpdatePlayer(c, &player)
+		gamemanagement.playerRepository.UpdatePlayer(c, player)
 		return nil
 	})
+	return nil
+}
+
+// nameTaken tells whether a name can not be told apart from the login name or the display name of one of the players
+func nameTaken(players []*dataaccess.Player, name string) bool {
+
+	key := dataaccess.NormalizeName(name)
+	for _, player := range players {
+		if dataaccess.NormalizeName(player.Name) == key || dataaccess.NormalizeName(player.DisplayName) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// PromotePlayer makes a player another admin of the game
+func (gamemanagement *gamemanagement) PromotePlayer(changeRoleTo to.ChangeRoleTo) error {
+	err := validator.New().Struct(changeRoleTo)
+	if err != nil {
+		return err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(changeRoleTo.GameCode)
+	if err != nil {
+		return err
+	}
+	player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(changeRoleTo.Name, game.ID)
+	if err == gorm.ErrRecordNotFound {
+		return gerr.ErrPlayerNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if dataaccess.CanManage(player.Role) {
+		return nil
+	}
+	player.Role = dataaccess.RoleAdmin.String()
 	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
-		gamemanagement.refreshGameStatus(c, &game)
+		gamemanagement.playerRepository.UpdatePlayer(c, &player)
 		return nil
 	})
 	return nil
 }
 
-// AddException adds a new exception so that PlayerA doesnt have to gift PlayerB
-func (gamemanagement *gamemanagement) AddException(addExceptionTo to.AddExceptionTo) error {
-	err := validator.New().Struct(addExceptionTo)
+// DemotePlayer makes an admin a normal player again, the last admin of a game keeps the role.
+// An organizer who is demoted takes part in the draw from then on.
+func (gamemanagement *gamemanagement) DemotePlayer(changeRoleTo to.ChangeRoleTo) error {
+	err := validator.New().Struct(changeRoleTo)
 	if err != nil {
 		return err
 	}
-	game, err := gamemanagement.gameRepository.FindGameByCode(addExceptionTo.GameCode)
+	game, err := gamemanagement.gameRepository.FindGameByCode(changeRoleTo.GameCode)
 	if err != nil {
 		return err
 	}
-	playerA, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(addExceptionTo.NameA, game.ID)
+	player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(changeRoleTo.Name, game.ID)
+	if err == gorm.ErrRecordNotFound {
+		return gerr.ErrPlayerNotFound
+	}
 	if err != nil {
 		return err
 	}
-	playerB, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(addExceptionTo.NameB, game.ID)
+	if !dataaccess.CanManage(player.Role) {
+		return nil
+	}
+	demoted := player
+	err = changeRole(&game, &demoted, dataaccess.RolePlayer)
 	if err != nil {
 		return err
 	}
-	_, err = gamemanagement.playerExceptionRepository.FindExceptionByIds(playerA.ID, playerB.ID, game.ID)
+	return gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		err := gamemanagement.checkKeepsAdmin(c, &player)
+		if err != nil {
+			return err
+		}
+		gamemanagement.playerRepository.UpdatePlayer(c, &demoted)
+		return nil
+	})
+}
+
+// TransferAdmin hands the admin role over to another player of the game
+func (gamemanagement *gamemanagement) TransferAdmin(transferAdminTo to.TransferAdminTo) error {
+	err := validator.New().Struct(transferAdminTo)
 	if err != nil {
-		playerException := dataaccess.PlayerException{PlayerA: playerA, PlayerB: playerB, GameID: game.ID}
-		gamemanagement.connection.NewTransaction(func(c gda.Connection) error {
-			gamemanagement.playerExceptionRepository.CreatePlayerException(c, &playerException)
-			return nil
-		})
-	} else {
-		return gerr.ErrPlayerExceptionAlreadyExists
+		return err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(transferAdminTo.GameCode)
+	if err != nil {
+		return err
+	}
+	from, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(transferAdminTo.From, game.ID)
+	if err == gorm.ErrRecordNotFound {
+		return gerr.ErrPlayerNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !dataaccess.CanManage(from.Role) {
+		return gerr.ErrPlayerNotAdmin
 	}
+	receiver, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(transferAdminTo.To, game.ID)
+	if err == gorm.ErrRecordNotFound {
+		return gerr.ErrPlayerNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if receiver.ID == from.ID {
+		return nil
+	}
+	err = changeRole(&game, &from, dataaccess.RolePlayer)
+	if err != nil {
+		return err
+	}
+	if !dataaccess.CanManage(receiver.Role) {
+		receiver.Role = dataaccess.RoleAdmin.String()
+	}
+	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		gamemanagement.playerRepository.UpdatePlayer(c, &receiver)
+		gamemanagement.playerRepository.UpdatePlayer(c, &from)
+		return nil
+	})
 	return nil
 }
 
-// GetBasicGameByCode fetches the game from the DB
-func (gamemanagement *gamemanagement) GetBasicGameByCode(code string) (to.GetBasicGameResponseTo, error) {
+// changeRole gives a player a new role. Players who start or stop taking part in the draw change the players
+// of the game, which is only allowed as long as the players can be edited.
+func changeRole(game *dataaccess.Game, player *dataaccess.Player, role dataaccess.Role) error {
 
-	if code == "" {
-		return to.GetBasicGameResponseTo{}, errors.New("Code must not be empty")
+	if dataaccess.TakesPart(player.Role) != dataaccess.TakesPart(role.String()) {
+		err := CheckGameOperation(game, OperationEditPlayers)
+		if err != nil {
+			return err
+		}
 	}
-	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	player.Role = role.String()
+	return nil
+}
+
+// checkKeepsAdmin makes sure that another admin or organizer is left when one loses the role or leaves the game.
+// It has to run in the transaction of the change, the admins and organizers stay locked until it ends, so two of
+// them can not step down at the same time.
+func (gamemanagement *gamemanagement) checkKeepsAdmin(c gda.Connection, player *dataaccess.Player) error {
+
+	if !dataaccess.CanManage(player.Role) {
+		return nil
+	}
+	players, err := gamemanagement.playerRepository.LockManagersByGameID(c, player.GameID)
 	if err != nil {
-		return to.GetBasicGameResponseTo{}, err
+		return err
 	}
-	gameResponseTo := to.GetBasicGameResponseTo{Title: game.Title, Description: game.Description, Code: game.Code}
-	return gameResponseTo, nil
+	for _, other := range players {
+		if other.ID != player.ID && dataaccess.CanManage(other.Role) {
+			return nil
+		}
+	}
+	return gerr.ErrLastAdmin
 }
 
-// GetFullGameByCode fetches the game from the DB
-func (gamemanagement *gamemanagement) GetFullGameByCode(code string, playerName string) (to.GetFullGameResponseTo, error) {
-	if code == "" {
-		return to.GetFullGameResponseTo{}, errors.New("Code must not be empty")
+// IssueInvite invites a player again, the invites issued before can not be used anymore.
+// A player who has already registered is not invited again, the invite would let anyone with the link take over the player.
+func (gamemanagement *gamemanagement) IssueInvite(invitePlayerTo to.InvitePlayerTo) (to.InviteTo, error) {
+	err := validator.New().Struct(invitePlayerTo)
+	if err != nil {
+		return to.InviteTo{}, err
 	}
-	if playerName == "" {
-		return to.GetFullGameResponseTo{}, errors.New("playerName must not be empty")
+	game, err := gamemanagement.gameRepository.FindGameByCode(invitePlayerTo.GameCode)
+	if err != nil {
+		return to.InviteTo{}, err
 	}
-	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(invitePlayerTo.Name, game.ID)
 	if err != nil {
-		return to.GetFullGameResponseTo{}, err
+		return to.InviteTo{}, gerr.ErrPlayerNotFound
 	}
-	gameResponseTo := to.GetFullGameResponseTo{Title: game.Title, Description: game.Description, Status: game.Status, Code: game.Code}
-	if game.Status == dataaccess.StatusDrawn.String() {
-		player, err := gamemanagement.playerRepository.FindPlayerWithAssociationsByNameAndGameID(playerName, game.ID)
-		if err != nil {
-			return to.GetFullGameResponseTo{}, errors.New("Player not found")
+	if player.Password != "" {
+		return to.InviteTo{}, gerr.ErrPlayerAlreadyRegistered
+	}
+	token, err := newInviteToken()
+	if err != nil {
+		return to.InviteTo{}, err
+	}
+	var inviteTo to.InviteTo
+	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		inviteTo = gamemanagement.createInvite(c, &player, token)
+		return nil
+	})
+	return inviteTo, nil
+}
+
+// RevokeInvite makes the invites of a player unusable
+func (gamemanagement *gamemanagement) RevokeInvite(invitePlayerTo to.InvitePlayerTo) error {
+	err := validator.New().Struct(invitePlayerTo)
+	if err != nil {
+		return err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(invitePlayerTo.GameCode)
+	if err != nil {
+		return err
+	}
+	player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(invitePlayerTo.Name, game.ID)
+	if err != nil {
+		return gerr.ErrPlayerNotFound
+	}
+	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		gamemanagement.inviteRepository.DeleteInvitesByPlayerID(c, player.ID)
+		return nil
+	})
+	return nil
+}
+
+// RegisterPlayerPassword registers the password for a player with the token of his/her invite and tells he/she is ready to go
+func (gamemanagement *gamemanagement) RegisterPlayerPassword(registerPlayerPasswordTo to.RegisterLoginPlayerPasswordTo) error {
+	err := validator.New().Struct(registerPlayerPasswordTo)
+	if err != nil {
+		return err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(registerPlayerPasswordTo.GameCode)
+	if err != nil {
+		return err
+	}
+	err = CheckGameOperation(&game, OperationRegisterPlayer)
+	if err != nil {
+		return err
+	}
+	player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(registerPlayerPasswordTo.Name, game.ID)
+	if err != nil {
+		return err
+	}
+	if registerPlayerPasswordTo.InviteToken == "" {
+		return gerr.ErrInvalidInvite
+	}
+	player.Password = gamemanagement.generatePassword(registerPlayerPasswordTo.Password)
+	player.Status = dataaccess.StatusReady.String()
+	// the invite is used up in the same transaction, so two requests with the same token can not both register
+	return gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		used, err := gamemanagement.inviteRepository.UseInvite(c, hashInviteToken(registerPlayerPasswordTo.InviteToken), player.ID, time.Now())
+		if err != nil {
+			return err
+		}
+		if !used {
+			return gerr.ErrInvalidInvite
+		}
+		gamemanagement.playerRepository.UpdatePlayer(c, &player)
+		gamemanagement.inviteRepository.DeleteInvitesByPlayerID(c, player.ID)
+		gamemanagement.refreshGameStatus(c, &game)
+		return nil
+	})
+}
+
+// AddException adds a new exception so that PlayerA doesnt have to gift PlayerB.
+// The exception is rejected if the game could not be drawn with it anymore.
+func (gamemanagement *gamemanagement) AddException(addExceptionTo to.AddExceptionTo) (to.FeasibilityResponseTo, error) {
+	err := validator.New().Struct(addExceptionTo)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(addExceptionTo.GameCode)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	err = CheckGameOperation(&game, OperationEditPlayers)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	playerA, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(addExceptionTo.NameA, game.ID)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	playerB, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(addExceptionTo.NameB, game.ID)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	_, err = gamemanagement.playerExceptionRepository.FindExceptionByIds(playerA.ID, playerB.ID, game.ID)
+	if err == nil {
+		return to.FeasibilityResponseTo{}, gerr.ErrPlayerExceptionAlreadyExists
+	}
+	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	exceptions, err := gamemanagement.playerExceptionRepository.FindExceptionsWithAssociationsByGameID(game.ID)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	playerException := dataaccess.PlayerException{PlayerA: playerA, PlayerB: playerB, GameID: game.ID}
+	feasibilityResponseTo, err := gamemanagement.analyzeFeasibility(&game, players, append(exceptions, &playerException))
+	if err != nil {
+		return feasibilityResponseTo, err
+	}
+	if !feasibilityResponseTo.Feasible {
+		return feasibilityResponseTo, gerr.ErrDrawInfeasible
+	}
+	gamemanagement.connection.NewTransaction(func(c gda.Connection) error {
+		gamemanagement.playerExceptionRepository.CreatePlayerException(c, &playerException)
+		return nil
+	})
+	return feasibilityResponseTo, nil
+}
+
+// RemoveException removes a single exception from a game
+func (gamemanagement *gamemanagement) RemoveException(removeExceptionTo to.RemoveExceptionTo) error {
+	err := validator.New().Struct(removeExceptionTo)
+	if err != nil {
+		return err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(removeExceptionTo.GameCode)
+	if err != nil {
+		return err
+	}
+	err = CheckGameOperation(&game, OperationEditPlayers)
+	if err != nil {
+		return err
+	}
+	playerA, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(removeExceptionTo.NameA, game.ID)
+	if err != nil {
+		return err
+	}
+	playerB, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(removeExceptionTo.NameB, game.ID)
+	if err != nil {
+		return err
+	}
+	playerException, err := gamemanagement.playerExceptionRepository.FindExceptionByIds(playerA.ID, playerB.ID, game.ID)
+	if err != nil {
+		return err
+	}
+	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		gamemanagement.playerExceptionRepository.DeleteExceptionByID(c, playerException.ID)
+		return nil
+	})
+	return nil
+}
+
+// ReplaceExceptions replaces all exceptions of a game by an exclusion matrix. All listed players have to belong
+// to the game, players not listed get no exceptions. Like a single exception, the matrix is rejected if the game
+// could not be drawn with it anymore.
+func (gamemanagement *gamemanagement) ReplaceExceptions(replaceExceptionsTo to.ReplaceExceptionsTo) (to.FeasibilityResponseTo, error) {
+	err := validator.New().Struct(replaceExceptionsTo)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	size := len(replaceExceptionsTo.Players)
+	if len(replaceExceptionsTo.Excluded) != size {
+		return to.FeasibilityResponseTo{}, gerr.ErrInvalidExceptionMatrix
+	}
+	for _, row := range replaceExceptionsTo.Excluded {
+		if len(row) != size {
+			return to.FeasibilityResponseTo{}, gerr.ErrInvalidExceptionMatrix
+		}
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(replaceExceptionsTo.GameCode)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	err = CheckGameOperation(&game, OperationEditPlayers)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	byName := make(map[string]*dataaccess.Player)
+	for _, player := range players {
+		byName[dataaccess.NormalizeName(player.Name)] = player
+	}
+	listed := make([]*dataaccess.Player, size)
+	seen := make(map[string]bool)
+	for i, name := range replaceExceptionsTo.Players {
+		key := dataaccess.NormalizeName(name)
+		player, found := byName[key]
+		if !found {
+			return to.FeasibilityResponseTo{}, gerr.ErrPlayerNotFound
+		}
+		if seen[key] {
+			return to.FeasibilityResponseTo{}, gerr.ErrInvalidExceptionMatrix
+		}
+		seen[key] = true
+		listed[i] = player
+	}
+	exceptions := make([]*dataaccess.PlayerException, 0)
+	for i, row := range replaceExceptionsTo.Excluded {
+		for j, excluded := range row {
+			// nobody gifts himself/herself anyway
+			if excluded && i != j {
+				exceptions = append(exceptions, &dataaccess.PlayerException{PlayerA: *listed[i], PlayerB: *listed[j], GameID: game.ID})
+			}
+		}
+	}
+	feasibilityResponseTo, err := gamemanagement.analyzeFeasibility(&game, players, exceptions)
+	if err != nil {
+		return feasibilityResponseTo, err
+	}
+	if !feasibilityResponseTo.Feasible {
+		return feasibilityResponseTo, gerr.ErrDrawInfeasible
+	}
+	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		gamemanagement.playerExceptionRepository.DeleteExceptionsByGameID(c, game.ID)
+		for _, playerException := range exceptions {
+			gamemanagement.playerExceptionRepository.CreatePlayerException(c, playerException)
+		}
+		return nil
+	})
+	return feasibilityResponseTo, nil
+}
+
+// CheckFeasibility tells whether a game can be drawn and explains what prevents it
+func (gamemanagement *gamemanagement) CheckFeasibility(code string) (to.FeasibilityResponseTo, error) {
+	if code == "" {
+		return to.FeasibilityResponseTo{}, errors.New("Code must not be empty")
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	exceptions, err := gamemanagement.playerExceptionRepository.FindExceptionsWithAssociationsByGameID(game.ID)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	return gamemanagement.analyzeFeasibility(&game, players, exceptions)
+}
+
+// GetBasicGameByCode fetches the game from the DB
+func (gamemanagement *gamemanagement) GetBasicGameByCode(code string) (to.GetBasicGameResponseTo, error) {
+
+	if code == "" {
+		return to.GetBasicGameResponseTo{}, errors.New("Code must not be empty")
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	if err != nil {
+		return to.GetBasicGameResponseTo{}, err
+	}
+	gameResponseTo := to.GetBasicGameResponseTo{Title: game.Title, Description: game.Description, Code: game.Code}
+	return gameResponseTo, nil
+}
+
+// GetFullGameByCode fetches the game from the DB
+func (gamemanagement *gamemanagement) GetFullGameByCode(code string, playerName string) (to.GetFullGameResponseTo, error) {
+	if code == "" {
+		return to.GetFullGameResponseTo{}, errors.New("Code must not be empty")
+	}
+	if playerName == "" {
+		return to.GetFullGameResponseTo{}, errors.New("playerName must not be empty")
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	if err != nil {
+		return to.GetFullGameResponseTo{}, err
+	}
+	gameResponseTo := to.GetFullGameResponseTo{Title: game.Title, Description: game.Description, Status: game.Status, Code: game.Code, Gifted: make([]string, 0), Budget: game.Budget, EventDate: game.EventDate}
+	if game.Status == dataaccess.StatusDrawn.String() {
+		player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(playerName, game.ID)
+		if err != nil {
+			return to.GetFullGameResponseTo{}, errors.New("Player not found")
+		}
+		assignments, err := gamemanagement.assignmentRepository.FindAssignmentsWithAssociationsByGiverID(player.ID)
+		if err != nil {
+			return to.GetFullGameResponseTo{}, err
+		}
+		for _, assignment := range assignments {
+			gameResponseTo.Gifted = append(gameResponseTo.Gifted, assignment.Receiver.DisplayName)
+		}
+		gameResponseTo.ReceiversChanged = player.ReceiversChanged
+	}
+	return gameResponseTo, nil
+}
+
+// GetPlayersByCode fetches the players of a game from the DB
+func (gamemanagement *gamemanagement) GetPlayersByCode(code string) ([]to.PlayerResponseTo, error) {
+	if code == "" {
+		return make([]to.PlayerResponseTo, 0), errors.New("Code must not be empty")
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	if err != nil {
+		return make([]to.PlayerResponseTo, 0), err
+	}
+	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
+	if err != nil {
+		return make([]to.PlayerResponseTo, 0), err
+	}
+	playerResponseTos := make([]to.PlayerResponseTo, 0)
+	for _, player := range players {
+		playerResponseTo := to.PlayerResponseTo{Name: player.Name, DisplayName: player.DisplayName, Status: player.Status}
+		playerResponseTos = append(playerResponseTos, playerResponseTo)
+	}
+	return playerResponseTos, nil
+}
+
+// GetPlayerRoleByCodeAndName fetches the player role in a game from the DB
+func (gamemanagement *gamemanagement) GetPlayerRoleByCodeAndName(code string, name string) (string, error) {
+	if code == "" {
+		return "", errors.New("Code must not be empty")
+	}
+	if name == "" {
+		return "", errors.New("Name must not be empty")
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	if err != nil {
+		return "", err
+	}
+	player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(name, game.ID)
+	if err == nil {
+		log.WithField("role", player.Role).Debug("Rolle gefunden")
+		return player.Role, nil
+	}
+	return "", err
+}
+
+// GetExceptionsByCode returns the draw exceptions in a game
+func (gamemanagement *gamemanagement) GetExceptionsByCode(code string) ([]to.ExceptionResponseTo, error) {
+	exceptionResponseTos := make([]to.ExceptionResponseTo, 0)
+	if code == "" {
+		return exceptionResponseTos, errors.New("Code must not be empty")
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	if err != nil {
+		return exceptionResponseTos, err
+	}
+	playerExceptions, err := gamemanagement.playerExceptionRepository.FindExceptionsWithAssociationsByGameID(game.ID)
+	if err != nil {
+		return exceptionResponseTos, err
+	}
+	for _, playerException := range playerExceptions {
+		exceptionResponseTo := to.ExceptionResponseTo{NameA: playerException.PlayerA.Name, NameB: playerException.PlayerB.Name, DisplayNameA: playerException.PlayerA.DisplayName, DisplayNameB: playerException.PlayerB.DisplayName}
+		exceptionResponseTos = append(exceptionResponseTos, exceptionResponseTo)
+	}
+	return exceptionResponseTos, err
+}
+
+// AddPreference adds a soft preference that player A should rather not gift player B, the weight tells how much
+func (gamemanagement *gamemanagement) AddPreference(addPreferenceTo to.AddPreferenceTo) error {
+	err := validator.New().Struct(addPreferenceTo)
+	if err != nil {
+		return err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(addPreferenceTo.GameCode)
+	if err != nil {
+		return err
+	}
+	err = CheckGameOperation(&game, OperationEditPlayers)
+	if err != nil {
+		return err
+	}
+	playerA, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(addPreferenceTo.NameA, game.ID)
+	if err != nil {
+		return err
+	}
+	playerB, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(addPreferenceTo.NameB, game.ID)
+	if err != nil {
+		return err
+	}
+	_, err = gamemanagement.playerPreferenceRepository.FindPreferenceByIds(playerA.ID, playerB.ID, game.ID)
+	if err == nil {
+		return gerr.ErrPlayerPreferenceAlreadyExists
+	}
+	playerPreference := dataaccess.PlayerPreference{PlayerA: playerA, PlayerB: playerB, Weight: addPreferenceTo.Weight, GameID: game.ID}
+	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		gamemanagement.playerPreferenceRepository.CreatePlayerPreference(c, &playerPreference)
+		return nil
+	})
+	return nil
+}
+
+// RemovePreference removes a soft preference from a game
+func (gamemanagement *gamemanagement) RemovePreference(removePreferenceTo to.RemovePreferenceTo) error {
+	err := validator.New().Struct(removePreferenceTo)
+	if err != nil {
+		return err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(removePreferenceTo.GameCode)
+	if err != nil {
+		return err
+	}
+	err = CheckGameOperation(&game, OperationEditPlayers)
+	if err != nil {
+		return err
+	}
+	playerA, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(removePreferenceTo.NameA, game.ID)
+	if err != nil {
+		return err
+	}
+	playerB, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(removePreferenceTo.NameB, game.ID)
+	if err != nil {
+		return err
+	}
+	playerPreference, err := gamemanagement.playerPreferenceRepository.FindPreferenceByIds(playerA.ID, playerB.ID, game.ID)
+	if err != nil {
+		return err
+	}
+	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		gamemanagement.playerPreferenceRepository.DeletePreferenceByID(c, playerPreference.ID)
+		return nil
+	})
+	return nil
+}
+
+// GetPreferencesByCode returns all soft preferences of a game
+func (gamemanagement *gamemanagement) GetPreferencesByCode(code string) ([]to.PreferenceResponseTo, error) {
+	preferenceResponseTos := make([]to.PreferenceResponseTo, 0)
+	if code == "" {
+		return preferenceResponseTos, errors.New("Code must not be empty")
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	if err != nil {
+		return preferenceResponseTos, err
+	}
+	playerPreferences, err := gamemanagement.playerPreferenceRepository.FindPreferencesWithAssociationsByGameID(game.ID)
+	if err != nil {
+		return preferenceResponseTos, err
+	}
+	for _, playerPreference := range playerPreferences {
+		preferenceResponseTo := to.PreferenceResponseTo{NameA: playerPreference.PlayerA.Name, NameB: playerPreference.PlayerB.Name, Weight: playerPreference.Weight, DisplayNameA: playerPreference.PlayerA.DisplayName, DisplayNameB: playerPreference.PlayerB.DisplayName}
+		preferenceResponseTos = append(preferenceResponseTos, preferenceResponseTo)
+	}
+	return preferenceResponseTos, nil
+}
+
+// AddHousehold adds a new household to a game
+func (gamemanagement *gamemanagement) AddHousehold(addHouseholdTo to.AddRemoveHouseholdTo) error {
+	err := validator.New().Struct(addHouseholdTo)
+	if err != nil {
+		return err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(addHouseholdTo.GameCode)
+	if err != nil {
+		return err
+	}
+	err = CheckGameOperation(&game, OperationEditPlayers)
+	if err != nil {
+		return err
+	}
+	_, err = gamemanagement.householdRepository.FindHouseholdByNameAndGameID(addHouseholdTo.Name, game.ID)
+	if err == nil {
+		return gerr.ErrHouseholdAlreadyExists
+	}
+	household := dataaccess.Household{Name: addHouseholdTo.Name, GameID: game.ID}
+	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		gamemanagement.householdRepository.CreateHousehold(c, &household)
+		return nil
+	})
+	return nil
+}
+
+// RemoveHousehold removes a household from a game, its members stay in the game without household
+func (gamemanagement *gamemanagement) RemoveHousehold(removeHouseholdTo to.AddRemoveHouseholdTo) error {
+	err := validator.New().Struct(removeHouseholdTo)
+	if err != nil {
+		return err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(removeHouseholdTo.GameCode)
+	if err != nil {
+		return err
+	}
+	err = CheckGameOperation(&game, OperationEditPlayers)
+	if err != nil {
+		return err
+	}
+	household, err := gamemanagement.householdRepository.FindHouseholdByNameAndGameID(removeHouseholdTo.Name, game.ID)
+	if err != nil {
+		return err
+	}
+	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		gamemanagement.playerRepository.ClearHouseholdByID(c, household.ID)
+		gamemanagement.householdRepository.DeleteHouseholdByID(c, household.ID)
+		return nil
+	})
+	return nil
+}
+
+// AssignPlayerToHousehold puts a player into a household, so that he/she never gifts or is gifted by other members.
+// The assignment is rejected if the game could not be drawn with it anymore.
+func (gamemanagement *gamemanagement) AssignPlayerToHousehold(assignHouseholdTo to.AssignHouseholdTo) (to.FeasibilityResponseTo, error) {
+	err := validator.New().Struct(assignHouseholdTo)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(assignHouseholdTo.GameCode)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	err = CheckGameOperation(&game, OperationEditPlayers)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(assignHouseholdTo.PlayerName, game.ID)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
+	}
+	player.HouseholdID = nil
+	if assignHouseholdTo.HouseholdName != "" {
+		household, err := gamemanagement.householdRepository.FindHouseholdByNameAndGameID(assignHouseholdTo.HouseholdName, game.ID)
+		if err != nil {
+			return to.FeasibilityResponseTo{}, err
 		}
-		gameResponseTo.Gifted = player.Gifted.Name
+		player.HouseholdID = &household.ID
 	}
-	return gameResponseTo, nil
-}
-
-// GetPlayersByCode fetches the players of a game from the DB
-func (gamemanagement *gamemanagement) GetPlayersByCode(code string) ([]to.PlayerResponseTo, error) {
-	if code == "" {
-		return make([]to.PlayerResponseTo, 0), errors.New("Code must not be empty")
+	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
+	if err != nil {
+		return to.FeasibilityResponseTo{}, err
 	}
-	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	exceptions, err := gamemanagement.playerExceptionRepository.FindExceptionsWithAssociationsByGameID(game.ID)
 	if err != nil {
-		return make([]to.PlayerResponseTo, 0), err
+		return to.FeasibilityResponseTo{}, err
 	}
-	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
+	for _, otherPlayer := range players {
+		if otherPlayer.ID == player.ID {
+			otherPlayer.HouseholdID = player.HouseholdID
+		}
+	}
+	feasibilityResponseTo, err := gamemanagement.analyzeFeasibility(&game, players, exceptions)
 	if err != nil {
-		return make([]to.PlayerResponseTo, 0), err
+		return feasibilityResponseTo, err
 	}
-	playerResponseTos := make([]to.PlayerResponseTo, 0)
-	for _, player := range players {
-		playerResponseTo := to.PlayerResponseTo{Name: player.Name, Status: player.Status}
-		playerResponseTos = append(playerResponseTos, playerResponseTo)
+	if !feasibilityResponseTo.Feasible {
+		return feasibilityResponseTo, gerr.ErrDrawInfeasible
 	}
 	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
-		gamemanagement.refreshGameStatus(c, &game)
+		gamemanagement.playerRepository.UpdatePlayer(c, &player)
 		return nil
 	})
-	return playerResponseTos, nil
+	return feasibilityResponseTo, nil
 }
 
-// GetPlayerRoleByCodeAndName fetches the player role in a game from the DB
-func (gamemanagement *gamemanagement) GetPlayerRoleByCodeAndName(code string, name string) (string, error) {
+// GetHouseholdsByCode returns the households of a game with their members
+func (gamemanagement *gamemanagement) GetHouseholdsByCode(code string) ([]to.HouseholdResponseTo, error) {
+	householdResponseTos := make([]to.HouseholdResponseTo, 0)
 	if code == "" {
-		return "", errors.New("Code must not be empty")
-	}
-	if name == "" {
-		return "", errors.New("Name must not be empty")
+		return householdResponseTos, errors.New("Code must not be empty")
 	}
 	game, err := gamemanagement.gameRepository.FindGameByCode(code)
 	if err != nil {
-		return "", err
-	}
-	player, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(name, game.ID)
-	if err == nil {
-		log.WithField("role", player.Role).Debug("Rolle gefunden")
-		return player.Role, nil
-	}
-	return "", err
-}
-
-// GetExceptionsByCode returns the draw exceptions in a game
-func (gamemanagement *gamemanagement) GetExceptionsByCode(code string) ([]to.ExceptionResponseTo, error) {
-	exceptionResponseTos := make([]to.ExceptionResponseTo, 0)
-	if code == "" {
-		return exceptionResponseTos, errors.New("Code must not be empty")
+		return householdResponseTos, err
 	}
-	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	households, err := gamemanagement.householdRepository.FindHouseholdsByGameID(game.ID)
 	if err != nil {
-		return exceptionResponseTos, err
+		return householdResponseTos, err
 	}
-	playerExceptions, err := gamemanagement.playerExceptionRepository.FindExceptionsWithAssociationsByGameID(game.ID)
+	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
 	if err != nil {
-		return exceptionResponseTos, err
+		return householdResponseTos, err
 	}
-	for _, playerException := range playerExceptions {
-		exceptionResponseTo := to.ExceptionResponseTo{NameA: playerException.PlayerA.Name, NameB: playerException.PlayerB.Name}
-		exceptionResponseTos = append(exceptionResponseTos, exceptionResponseTo)
+	for _, household := range households {
+		householdResponseTo := to.HouseholdResponseTo{Name: household.Name, Players: make([]string, 0), DisplayNames: make([]string, 0)}
+		for _, player := range players {
+			if player.HouseholdID != nil && *player.HouseholdID == household.ID {
+				householdResponseTo.Players = append(householdResponseTo.Players, player.Name)
+				householdResponseTo.DisplayNames = append(householdResponseTo.DisplayNames, player.DisplayName)
+			}
+		}
+		householdResponseTos = append(householdResponseTos, householdResponseTo)
 	}
-	return exceptionResponseTos, err
+	return householdResponseTos, nil
 }
 
-// LoginPlayer logs in a player
-func (gamemanagement *gamemanagement) LoginPlayer(loginPlayerPasswordTo to.RegisterLoginPlayerPasswordTo) to.RegisterLoginPlayerPasswordResponseTo {
+// LoginPlayer logs in a player. Every login is counted as failed before the password is checked, per client IP,
+// per player and per player and client IP, and only taken back when it succeeds: after a few failures the next try
+// has to wait exponentially longer, and too many lock the client for a while. If the failures can not be counted
+// the login is rejected. Rejected logins are audited.
+func (gamemanagement *gamemanagement) LoginPlayer(loginPlayerPasswordTo to.RegisterLoginPlayerPasswordTo, clientIP string) to.RegisterLoginPlayerPasswordResponseTo {
 	var player dataaccess.Player
 	var loginPlayerPasswordResponseTo to.RegisterLoginPlayerPasswordResponseTo
-	err := validator.New().Struct(loginPlayerPasswordTo)
-	if err != nil {
-		gamemanagement.writeLoginError(&loginPlayerPasswordResponseTo)
+	now := time.Now()
+	keys := loginThrottleKeys(clientIP, loginPlayerPasswordTo.GameCode, loginPlayerPasswordTo.Name)
+	reject := func(reason string) to.RegisterLoginPlayerPasswordResponseTo {
+		gamemanagement.auditLogin(loginPlayerPasswordTo.GameCode, loginPlayerPasswordTo.Name, clientIP, reason)
+		if loginPlayerPasswordResponseTo.Message == "" {
+			gamemanagement.writeLoginError(&loginPlayerPasswordResponseTo)
+		}
 		return loginPlayerPasswordResponseTo
 	}
+	retryAfter, err := gamemanagement.countLoginAttempt(keys, now)
+	if err != nil {
+		// without counting the attempt the password could be guessed without limit
+		log.Errorln("Failed to count the login attempt", err)
+		loginPlayerPasswordResponseTo.Message = "Die Anmeldung ist gerade nicht möglich, bitte versuche es später erneut"
+		return reject("throttleUnavailable")
+	}
+	if retryAfter > 0 {
+		loginPlayerPasswordResponseTo.Message = "Zu viele Fehlversuche, bitte warte etwas und versuche es dann erneut"
+		loginPlayerPasswordResponseTo.RetryAfter = int(math.Ceil(retryAfter.Seconds()))
+		return reject("locked")
+	}
+	err = validator.New().Struct(loginPlayerPasswordTo)
+	if err != nil {
+		return reject("invalid")
+	}
 	game, err := gamemanagement.gameRepository.FindGameByCode(loginPlayerPasswordTo.GameCode)
 	if err != nil {
-		gamemanagement.writeLoginError(&loginPlayerPasswordResponseTo)
-		return loginPlayerPasswordResponseTo
+		return reject("unknownGame")
 	}
 	player, err = gamemanagement.playerRepository.FindPlayerByNameAndGameID(loginPlayerPasswordTo.Name, game.ID)
 	if err != nil {
-		gamemanagement.writeLoginError(&loginPlayerPasswordResponseTo)
-		return loginPlayerPasswordResponseTo
+		return reject("unknownPlayer")
 	}
 	if player.Password == "" {
-		gamemanagement.RegisterPlayerPassword(loginPlayerPasswordTo)
-	} else {
-		err = bcrypt.CompareHashAndPassword([]byte(player.Password), []byte(loginPlayerPasswordTo.Password))
-		if err != nil {
-			gamemanagement.writeLoginError(&loginPlayerPasswordResponseTo)
-			return loginPlayerPasswordResponseTo
-		}
+		loginPlayerPasswordResponseTo.Message = "Bitte registriere dich zuerst über deinen Einladungslink"
+		return reject("notRegistered")
 	}
+	err = bcrypt.CompareHashAndPassword([]byte(player.Password), []byte(loginPlayerPasswordTo.Password))
+	if err != nil {
+		return reject("wrongPassword")
+	}
+	gamemanagement.acceptLogin(keys)
 	loginPlayerPasswordResponseTo.Ok = true
 	return loginPlayerPasswordResponseTo
 }
@@ -318,51 +1349,201 @@ func (gamemanagement *gamemanagement) DrawGame(drawGameTo to.DrawGameTo) (to.Dra
 	if err != nil {
 		return to.DrawGameResponseTo{}, err
 	}
-	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
+	err = CheckGameOperation(&game, OperationDraw)
 	if err != nil {
 		return to.DrawGameResponseTo{}, err
 	}
-	exceptions, err := gamemanagement.playerExceptionRepository.FindExceptionsWithAssociationsByGameID(game.ID)
+	// the audit is revealed after the event, a draw after it would be revealed right away
+	if game.EventDate != nil && !game.EventDate.After(time.Now()) {
+		return to.DrawGameResponseTo{}, gerr.ErrEventDatePassed
+	}
+	context, err := gamemanagement.loadDrawContext(&game)
 	if err != nil {
 		return to.DrawGameResponseTo{}, err
 	}
-	var lots map[*dataaccess.Player]*dataaccess.Player = make(map[*dataaccess.Player]*dataaccess.Player)
-	tries := 0
-	ok := false
-	for !ok && tries < 100 {
-		remaining := make([]*dataaccess.Player, len(players))
-		copy(remaining, players)
-		for _, player := range players {
-			randomNumber := gamemanagement.random.NextInt(len(remaining))
-			lots[player] = remaining[randomNumber]
-			log.WithFields(log.Fields{"giftee": player.Name, "gifted": lots[player].Name}).Debug("Los")
-			remaining = append(remaining[:randomNumber], remaining[randomNumber+1:]...)
-			log.WithField("remaining", len(remaining)).Debug("Remaining")
-			singleOk := gamemanagement.isAllowed(exceptions, player, lots[player])
-			if !singleOk {
-				break
+	players := context.Players
+	drawer, err := gamemanagement.drawStrategies.Drawer(game.DrawStrategy)
+	if err != nil {
+		return to.DrawGameResponseTo{}, err
+	}
+	lots := make(map[*dataaccess.Player][]*dataaccess.Player)
+	rules := gamemanagement.drawRules(&game)
+	gifts := gamemanagement.giftsPerPlayer(&game)
+	input := gamemanagement.drawStrategies.Input(context)
+	// the seed is kept, so that the draw can be repeated and verified after the event
+	seed := gamemanagement.random.NextSeed()
+	result := drawer.Draw(input, drawRandomizers[drawAlgorithmVersion](seed))
+	if result.GaveUp {
+		return to.DrawGameResponseTo{}, gerr.ErrDrawSearchGaveUp
+	}
+	ok := result.Ok && checkAssignment(input, result.Assignment)
+	if ok {
+		for giver, receivers := range result.Assignment {
+			for _, receiver := range receivers {
+				lots[players[giver]] = append(lots[players[giver]], players[receiver])
+				log.WithFields(log.Fields{"giftee": players[giver].Name, "gifted": players[receiver].Name}).Debug("Los")
 			}
 		}
-		ok = gamemanagement.checkResult(exceptions, lots)
-		tries++
 	}
 	drawGameResponseTo := to.DrawGameResponseTo{}
 	if ok {
-		game.Status = dataaccess.StatusDrawn.String()
+		drawAudit, err := gamemanagement.newDrawAudit(&game, seed, input, result.Assignment)
+		if err != nil {
+			return to.DrawGameResponseTo{}, err
+		}
+		err = TransitionGame(&game, dataaccess.StatusDrawn)
+		if err != nil {
+			return to.DrawGameResponseTo{}, err
+		}
 		gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
-			gamemanagement.saveLots(c, lots)
+			gamemanagement.saveLots(c, &game, players, lots)
+			gamemanagement.drawAuditRepository.CreateDrawAudit(c, &drawAudit)
 			gamemanagement.gameRepository.UpdateGame(c, &game)
 			return nil
 		})
+		drawGameResponseTo.Commitment = drawAudit.Commitment
 	} else {
-		log.Warn("Keine plausible Auslosung gefunden")
-		drawGameResponseTo.Message = "Nach 100 Versuchen wurde kein plausibles Ergebnis gefunden. Bitte nochmal versuchen oder weniger Ausnahmen definieren."
+		log.Warn("Keine plausible Auslosung möglich")
+		drawGameResponseTo.Message = gamemanagement.drawFailureMessage(rules, gifts)
+	}
+	if ok {
+		drawGameResponseTo.Message = gamemanagement.historyMessage(input, result)
 	}
 	drawGameResponseTo.Ok = ok
+	if ok {
+		drawGameResponseTo.Score = result.Score
+	}
 	return drawGameResponseTo, nil
 }
 
-// ResetGame resets a game
+// PreviewDraw checks whether a game can be drawn and computes statistics about its draw, without revealing or saving any lots
+func (gamemanagement *gamemanagement) PreviewDraw(code string) (to.DrawPreviewTo, error) {
+	if code == "" {
+		return to.DrawPreviewTo{}, errors.New("Code must not be empty")
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	if err != nil {
+		return to.DrawPreviewTo{}, err
+	}
+	context, err := gamemanagement.loadDrawContext(&game)
+	if err != nil {
+		return to.DrawPreviewTo{}, err
+	}
+	drawer, err := gamemanagement.drawStrategies.Drawer(game.DrawStrategy)
+	if err != nil {
+		return to.DrawPreviewTo{}, err
+	}
+	input := gamemanagement.drawStrategies.Input(context)
+	drawPreviewTo := to.DrawPreviewTo{ConstrainedPlayers: constrainedPlayers(input)}
+	result := drawer.Draw(input, gamemanagement.random)
+	if result.GaveUp {
+		return to.DrawPreviewTo{}, gerr.ErrDrawSearchGaveUp
+	}
+	drawPreviewTo.Feasible = result.Ok && checkAssignment(input, result.Assignment)
+	if drawPreviewTo.Feasible {
+		drawPreviewTo.Score = result.Score
+		drawPreviewTo.Message = gamemanagement.historyMessage(input, result)
+	} else {
+		drawPreviewTo.Message = gamemanagement.drawFailureMessage(gamemanagement.drawRules(&game), input.GiftsPerPlayer)
+	}
+	drawPreviewTo.ValidAssignments, drawPreviewTo.Exact = countAssignments(input.Allowed, gamemanagement.random)
+	if input.SingleCycle {
+		if drawPreviewTo.Feasible {
+			drawPreviewTo.SingleCycleProbability = 1
+		}
+	} else {
+		drawPreviewTo.SingleCycleProbability = singleCycleProbability(input.Allowed, drawPreviewTo.ValidAssignments, gamemanagement.random)
+	}
+	return drawPreviewTo, nil
+}
+
+// GetDrawAudit returns the audit of the current draw of a game. Seed, input and result are only revealed after the event date,
+// as are the lots after every repair.
+func (gamemanagement *gamemanagement) GetDrawAudit(code string) (to.DrawAuditTo, error) {
+	if code == "" {
+		return to.DrawAuditTo{}, errors.New("Code must not be empty")
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	if err != nil {
+		return to.DrawAuditTo{}, err
+	}
+	drawAudit, err := gamemanagement.currentDrawAudit(&game)
+	if err != nil {
+		return to.DrawAuditTo{}, err
+	}
+	drawAuditTo := to.DrawAuditTo{AlgorithmVersion: drawAudit.AlgorithmVersion, Commitment: drawAudit.Commitment, DrawnAt: drawAudit.CreatedAt}
+	drawAuditTo.Repairs, err = decodeRepairs(&drawAudit)
+	if err != nil {
+		return to.DrawAuditTo{}, err
+	}
+	if !revealsAudit(&game, &drawAudit, time.Now()) {
+		for i := range drawAuditTo.Repairs {
+			drawAuditTo.Repairs[i].Players = nil
+			drawAuditTo.Repairs[i].Result = nil
+		}
+		return drawAuditTo, nil
+	}
+	err = json.Unmarshal([]byte(drawAudit.Input), &drawAuditTo.Input)
+	if err != nil {
+		return to.DrawAuditTo{}, err
+	}
+	if drawAudit.Result != "" {
+		err = json.Unmarshal([]byte(drawAudit.Result), &drawAuditTo.Result)
+	} else {
+		drawAuditTo.Result, err = gamemanagement.legacyAuditResult(&game, drawAuditTo.Input)
+	}
+	if err != nil {
+		return to.DrawAuditTo{}, err
+	}
+	drawAuditTo.Revealed = true
+	drawAuditTo.Seed = drawAudit.Seed
+	return drawAuditTo, nil
+}
+
+// legacyAuditResult rebuilds the result of an audit written before the lots were stored with it. The players are matched
+// by their names, a lot of a player who is not part of the input makes the audit outdated.
+func (gamemanagement *gamemanagement) legacyAuditResult(game *dataaccess.Game, input to.DrawInputTo) ([][]int, error) {
+
+	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := gamemanagement.assignmentRepository.FindAssignmentsByGameID(game.ID)
+	if err != nil {
+		return nil, err
+	}
+	inputIndex := make(map[string]int)
+	for i, name := range input.Players {
+		inputIndex[dataaccess.NormalizeName(name)] = i
+	}
+	index := make(map[uint]int)
+	for _, player := range players {
+		if i, found := inputIndex[dataaccess.NormalizeName(player.Name)]; found {
+			index[player.ID] = i
+		}
+	}
+	result := make([][]int, len(input.Players))
+	for _, assignment := range assignments {
+		giver, giverFound := index[assignment.GiverID]
+		receiver, receiverFound := index[assignment.ReceiverID]
+		if !giverFound || !receiverFound {
+			return nil, gerr.ErrDrawAuditOutdated
+		}
+		result[giver] = append(result[giver], receiver)
+	}
+	for _, receivers := range result {
+		sort.Ints(receivers)
+	}
+	return result, nil
+}
+
+// VerifyDraw checks a revealed draw with the registered draw strategies
+func (gamemanagement *gamemanagement) VerifyDraw(drawAuditTo to.DrawAuditTo) error {
+
+	return gamemanagement.drawStrategies.Verify(drawAuditTo)
+}
+
+// ResetGame resets a drawn game, so that it can be changed and drawn again
 func (gamemanagement *gamemanagement) ResetGame(code string) error {
 	if code == "" {
 		return errors.New("Code must not be empty")
@@ -371,44 +1552,293 @@ func (gamemanagement *gamemanagement) ResetGame(code string) error {
 	if err != nil {
 		return err
 	}
-	game.Status = dataaccess.StatusReady.String()
+	err = CheckGameOperation(&game, OperationReset)
+	if err != nil {
+		return err
+	}
+	assignments, err := gamemanagement.assignmentRepository.FindAssignmentsByGameID(game.ID)
+	if err != nil {
+		return err
+	}
+	// the lots are archived as the next round, so that they can be restored if the game was reset by mistake
+	drawRound := dataaccess.DrawRound{GameID: game.ID, Number: 1, Assignments: make([]dataaccess.RoundAssignment, 0, len(assignments))}
+	previousRound, err := gamemanagement.drawRoundRepository.FindLatestDrawRoundByGameID(game.ID)
+	if err == nil {
+		drawRound.Number = previousRound.Number + 1
+	}
+	drawAudit, err := gamemanagement.drawAuditRepository.FindLatestDrawAuditByGameID(game.ID)
+	if err == nil {
+		drawRound.DrawAuditID = &drawAudit.ID
+	}
+	for _, assignment := range assignments {
+		drawRound.Assignments = append(drawRound.Assignments, dataaccess.RoundAssignment{GiverID: assignment.GiverID, ReceiverID: assignment.ReceiverID})
+	}
+	err = TransitionGame(&game, dataaccess.StatusReset)
+	if err != nil {
+		return err
+	}
+	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		if len(assignments) > 0 {
+			gamemanagement.drawRoundRepository.CreateDrawRound(c, &drawRound)
+		}
+		gamemanagement.assignmentRepository.DeleteAssignmentsByGameID(c, game.ID)
+		gamemanagement.gameRepository.UpdateGame(c, &game)
+		return nil
+	})
+	return nil
+}
+
+// RestoreGame restores the latest draw round of a reset game, in case it has been reset by mistake.
+// The round can only be restored as long as its lots still fit the players and exceptions of the game.
+func (gamemanagement *gamemanagement) RestoreGame(code string) error {
+	if code == "" {
+		return errors.New("Code must not be empty")
+	}
+	game, err := gamemanagement.gameRepository.FindGameByCode(code)
+	if err != nil {
+		return err
+	}
+	err = CheckGameOperation(&game, OperationRestore)
+	if err != nil {
+		return err
+	}
+	drawRound, err := gamemanagement.drawRoundRepository.FindLatestDrawRoundByGameID(game.ID)
+	if err != nil {
+		return err
+	}
+	context, err := gamemanagement.loadDrawContext(&game)
+	if err != nil {
+		return err
+	}
+	players := context.Players
+	index := playerIndices(players)
+	assignment := make([][]int, len(players))
+	for _, roundAssignment := range drawRound.Assignments {
+		giver, giverFound := index[roundAssignment.GiverID]
+		receiver, receiverFound := index[roundAssignment.ReceiverID]
+		if !giverFound || !receiverFound {
+			return gerr.ErrDrawRoundOutdated
+		}
+		assignment[giver] = append(assignment[giver], receiver)
+	}
+	for giver := range assignment {
+		sort.Ints(assignment[giver])
+	}
+	if !checkAssignment(gamemanagement.drawStrategies.Input(context), assignment) {
+		return gerr.ErrDrawRoundOutdated
+	}
+	var drawAudit *dataaccess.DrawAudit
+	if drawRound.DrawAuditID != nil {
+		archivedAudit, err := gamemanagement.drawAuditRepository.FindDrawAuditByID(*drawRound.DrawAuditID)
+		if err != nil {
+			return err
+		}
+		// the audit is published again as the latest one, so that the restored lots can still be verified
+		drawAudit = &dataaccess.DrawAudit{GameID: game.ID, AlgorithmVersion: archivedAudit.AlgorithmVersion, Seed: archivedAudit.Seed, Commitment: archivedAudit.Commitment, Input: archivedAudit.Input, Result: archivedAudit.Result, Repairs: archivedAudit.Repairs}
+	}
+	err = TransitionGame(&game, dataaccess.StatusDrawn)
+	if err != nil {
+		return err
+	}
 	gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		gamemanagement.assignmentRepository.DeleteAssignmentsByGameID(c, game.ID)
+		for _, roundAssignment := range drawRound.Assignments {
+			restoredAssignment := dataaccess.Assignment{GameID: game.ID, GiverID: roundAssignment.GiverID, ReceiverID: roundAssignment.ReceiverID}
+			gamemanagement.assignmentRepository.CreateAssignment(c, &restoredAssignment)
+		}
+		if drawAudit != nil {
+			gamemanagement.drawAuditRepository.CreateDrawAudit(c, drawAudit)
+		}
+		gamemanagement.drawRoundRepository.DeleteDrawRoundByID(c, drawRound.ID)
 		gamemanagement.gameRepository.UpdateGame(c, &game)
 		return nil
 	})
 	return nil
 }
 
-func (gamemanagement *gamemanagement) saveLots(c gda.Connection, lots map[*dataaccess.Player]*dataaccess.Player) {
+// loadDrawContext loads everything the constraints of a draw may look at
+func (gamemanagement *gamemanagement) loadDrawContext(game *dataaccess.Game) (*DrawContext, error) {
 
-	for giftee, gifted := range lots {
+	players, err := gamemanagement.playerRepository.FindPlayersByGameID(game.ID)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := gamemanagement.playerExceptionRepository.FindExceptionsWithAssociationsByGameID(game.ID)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := gamemanagement.playerPreferenceRepository.FindPreferencesWithAssociationsByGameID(game.ID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := gamemanagement.loadPairingHistory(game)
+	if err != nil {
+		return nil, err
+	}
+	return &DrawContext{Game: game, Players: participants(players), Exceptions: exceptions, Preferences: preferences, history: history}, nil
+}
+
+// participants leaves out the organizers, who do not take part in the draw
+func participants(players []*dataaccess.Player) []*dataaccess.Player {
+
+	participants := make([]*dataaccess.Player, 0, len(players))
+	for _, player := range players {
+		if dataaccess.TakesPart(player.Role) {
+			participants = append(participants, player)
+		}
+	}
+	return participants
+}
+
+// historyMessage tells whether pairings of previous years had to be allowed again
+func (gamemanagement *gamemanagement) historyMessage(input to.DrawInputTo, result DrawResult) string {
+
+	if result.Years == 0 && len(input.History) > 0 {
+		return "Paarungen aus den Vorjahren ließen sich nicht vermeiden und wurden wieder zugelassen."
+	}
+	if result.Years < len(input.History) {
+		return fmt.Sprintf("Nur Paarungen aus den letzten %d Jahren wurden vermieden, ältere Paarungen wurden wieder zugelassen.", result.Years)
+	}
+	return ""
+}
+
+func (gamemanagement *gamemanagement) drawRules(game *dataaccess.Game) drawRules {
+
+	return drawRules{singleCycle: game.DrawMode == dataaccess.DrawModeSingleCycle.String(), forbidMutualPairs: game.ForbidMutualPairs}
+}
+
+func (gamemanagement *gamemanagement) giftsPerPlayer(game *dataaccess.Game) int {
 
-		giftee.GiftedID = &gifted.ID
-		gamemanagement.playerRepository.UpdatePlayer(c, giftee)
+	if game.GiftsPerPlayer < 1 {
+		return 1
 	}
+	return game.GiftsPerPlayer
 }
 
-func (gamemanagement *gamemanagement) checkResult(exceptions []*dataaccess.PlayerException, lots map[*dataaccess.Player]*dataaccess.Player) bool {
+func (gamemanagement *gamemanagement) drawFailureMessage(rules drawRules, gifts int) string {
 
-	for giftee, gifted := range lots {
-		if !gamemanagement.isAllowed(exceptions, giftee, gifted) {
-			return false
+	if gifts > 1 {
+		if rules.singleCycle {
+			return "Eine Auslosung in einem einzigen Kreis ist nur mit einem Geschenk pro Person möglich. Bitte den Modus ändern."
 		}
+		return fmt.Sprintf("Mit den definierten Ausnahmen kann nicht jede Person %d Personen beschenken und von %d Personen beschenkt werden. Bitte weniger Ausnahmen oder weniger Geschenke pro Person definieren.", gifts, gifts)
+	}
+	if rules.singleCycle {
+		return "Mit den definierten Ausnahmen ist keine Auslosung in einem einzigen Kreis möglich. Bitte weniger Ausnahmen definieren oder den Modus ändern."
+	}
+	if rules.forbidMutualPairs {
+		return "Mit den definierten Ausnahmen ist keine Auslosung ohne gegenseitiges Beschenken möglich. Bitte weniger Ausnahmen definieren oder gegenseitiges Beschenken erlauben."
 	}
-	return true
+	return "Mit den definierten Ausnahmen ist keine Auslosung möglich. Bitte weniger Ausnahmen definieren."
 }
 
-func (gamemanagement *gamemanagement) isAllowed(exceptions []*dataaccess.PlayerException, giftee *dataaccess.Player, gifted *dataaccess.Player) bool {
+// analyzeFeasibility checks whether the players can be drawn. If not, the smallest group of players
+// which can not be served and the exceptions which keep them from gifting other players are returned.
+// If the search for an assignment gives up, it is unknown whether the players can be drawn and ErrDrawSearchGaveUp is returned.
+func (gamemanagement *gamemanagement) analyzeFeasibility(game *dataaccess.Game, players []*dataaccess.Player, exceptions []*dataaccess.PlayerException) (to.FeasibilityResponseTo, error) {
 
-	if giftee.ID == gifted.ID {
-		return false
+	players = participants(players)
+	feasibilityResponseTo := to.FeasibilityResponseTo{Feasible: true, Players: make([]string, 0), Exceptions: make([]to.ExceptionResponseTo, 0), Households: make([]string, 0)}
+	allowed := gamemanagement.allowedMatrix(game, exceptions, players)
+	rules := gamemanagement.drawRules(game)
+	gifts := gamemanagement.giftsPerPlayer(game)
+	budget := newSearchBudget()
+	if gifts > 1 {
+		// the explanation by a conflicting group of players only exists for one gift per player
+		if _, ok := drawMultipleAssignment(allowed, gifts, rules, gamemanagement.random, budget); !ok {
+			if budget.exhausted {
+				return to.FeasibilityResponseTo{}, gerr.ErrDrawSearchGaveUp
+			}
+			feasibilityResponseTo.Feasible = false
+			feasibilityResponseTo.Message = gamemanagement.drawFailureMessage(rules, gifts)
+		}
+		return feasibilityResponseTo, nil
+	}
+	conflict, found := findConflict(allowed)
+	if !found {
+		if _, ok := drawAssignment(allowed, rules, gamemanagement.random, budget); !ok {
+			if budget.exhausted {
+				return to.FeasibilityResponseTo{}, gerr.ErrDrawSearchGaveUp
+			}
+			feasibilityResponseTo.Feasible = false
+			feasibilityResponseTo.Message = gamemanagement.drawFailureMessage(rules, gifts)
+		}
+		return feasibilityResponseTo, nil
+	}
+	feasibilityResponseTo.Feasible = false
+	inConflict := make(map[uint]bool)
+	for _, giver := range conflict.givers {
+		inConflict[players[giver].ID] = true
+		feasibilityResponseTo.Players = append(feasibilityResponseTo.Players, players[giver].Name)
+	}
+	receivable := make(map[uint]bool)
+	for _, receiver := range conflict.receivers {
+		receivable[players[receiver].ID] = true
 	}
 	for _, playerException := range exceptions {
-		if playerException.PlayerA.ID == giftee.ID && playerException.PlayerB.ID == gifted.ID {
-			return false
+		if inConflict[playerException.PlayerA.ID] && !receivable[playerException.PlayerB.ID] {
+			exceptionResponseTo := to.ExceptionResponseTo{NameA: playerException.PlayerA.Name, NameB: playerException.PlayerB.Name, DisplayNameA: playerException.PlayerA.DisplayName, DisplayNameB: playerException.PlayerB.DisplayName}
+			feasibilityResponseTo.Exceptions = append(feasibilityResponseTo.Exceptions, exceptionResponseTo)
+		}
+	}
+	blockingHouseholds := make(map[uint]bool)
+	for _, giver := range conflict.givers {
+		for _, member := range players {
+			if gamemanagement.sameHousehold(players[giver], member) && member.ID != players[giver].ID && !receivable[member.ID] {
+				blockingHouseholds[*member.HouseholdID] = true
+			}
+		}
+	}
+	if len(blockingHouseholds) > 0 {
+		households, _ := gamemanagement.householdRepository.FindHouseholdsByGameID(game.ID)
+		for _, household := range households {
+			if blockingHouseholds[household.ID] {
+				feasibilityResponseTo.Households = append(feasibilityResponseTo.Households, household.Name)
+			}
+		}
+	}
+	feasibilityResponseTo.Message = fmt.Sprintf("Für %s gibt es zusammen nur %d mögliche Beschenkte. Bitte eine der angezeigten Ausnahmen oder Haushalte auflösen.", strings.Join(feasibilityResponseTo.Players, ", "), len(conflict.receivers))
+	return feasibilityResponseTo, nil
+}
+
+func (gamemanagement *gamemanagement) saveLots(c gda.Connection, game *dataaccess.Game, players []*dataaccess.Player, lots map[*dataaccess.Player][]*dataaccess.Player) {
+
+	gamemanagement.assignmentRepository.DeleteAssignmentsByGameID(c, game.ID)
+	gamemanagement.playerRepository.ClearReceiversChangedByGameID(c, game.ID)
+	for _, giftee := range players {
+		for _, gifted := range lots[giftee] {
+			assignment := dataaccess.Assignment{GameID: game.ID, GiverID: giftee.ID, ReceiverID: gifted.ID}
+			gamemanagement.assignmentRepository.CreateAssignment(c, &assignment)
 		}
 	}
-	return true
+}
+
+func (gamemanagement *gamemanagement) allowedMatrix(game *dataaccess.Game, exceptions []*dataaccess.PlayerException, players []*dataaccess.Player) [][]bool {
+
+	return gamemanagement.drawStrategies.Input(&DrawContext{Game: game, Players: players, Exceptions: exceptions}).Allowed
+}
+
+func (gamemanagement *gamemanagement) newDrawAudit(game *dataaccess.Game, seed int64, input to.DrawInputTo, assignment [][]int) (dataaccess.DrawAudit, error) {
+
+	commitment, err := drawCommitment(drawAlgorithmVersion, seed, input)
+	if err != nil {
+		return dataaccess.DrawAudit{}, err
+	}
+	encodedInput, err := json.Marshal(input)
+	if err != nil {
+		return dataaccess.DrawAudit{}, err
+	}
+	// the lots are kept as indices of the input, so that renamed or changed players do not change the revealed result
+	encodedResult, err := json.Marshal(assignment)
+	if err != nil {
+		return dataaccess.DrawAudit{}, err
+	}
+	return dataaccess.DrawAudit{GameID: game.ID, AlgorithmVersion: drawAlgorithmVersion, Seed: seed, Commitment: commitment, Input: string(encodedInput), Result: string(encodedResult)}, nil
+}
+
+func (gamemanagement *gamemanagement) sameHousehold(playerA *dataaccess.Player, playerB *dataaccess.Player) bool {
+
+	return playerA.HouseholdID != nil && playerB.HouseholdID != nil && *playerA.HouseholdID == *playerB.HouseholdID
 }
 
 func (gamemanagement *gamemanagement) writeLoginError(loginPlayerPasswordResponseTo *to.RegisterLoginPlayerPasswordResponseTo) {
@@ -427,8 +1857,10 @@ func (gamemanagement *gamemanagement) generatePassword(plainPassword string) str
 	return string(hash)
 }
 
+// refreshGameStatus makes a created or waiting game ready as soon as all its players are ready
 func (gamemanagement *gamemanagement) refreshGameStatus(c gda.Connection, game *dataaccess.Game) error {
-	if game.Status == dataaccess.StatusDrawn.String() {
+	status := GameStatus(game)
+	if status != dataaccess.StatusCreated && status != dataaccess.StatusWaiting {
 		return nil
 	}
 	_, exists, err := gamemanagement.playerRepository.FindFirstUnreadyPlayerByGameID(game.ID)
@@ -436,7 +1868,10 @@ func (gamemanagement *gamemanagement) refreshGameStatus(c gda.Connection, game *
 		return err
 	}
 	if !exists {
-		game.Status = dataaccess.StatusReady.String()
+		err = TransitionGame(game, dataaccess.StatusReady)
+		if err != nil {
+			return err
+		}
 		gamemanagement.gameRepository.UpdateGame(c, game)
 	}
 	return err
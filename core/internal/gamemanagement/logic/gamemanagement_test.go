@@ -1,6 +1,10 @@
 package logic_test
 
 import (
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-playground/validator/v10"
 	. "github.com/onsi/ginkgo"
@@ -10,7 +14,6 @@ import (
 	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/errors"
 	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
 	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
-	gl "github.com/yoktobit/secretsanta/internal/general/logic"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -31,6 +34,26 @@ func expectDefaultQueryWithNoResult(mock sqlmock.Sqlmock) {
 	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRowsWithColumnDefinition(sqlmock.NewColumn("id")))
 }
 
+type failingLoginAttemptStore struct{}
+
+func (failingLoginAttemptStore) AddFailure(key string, at time.Time, since time.Time) (logic.LoginFailures, error) {
+	return logic.LoginFailures{}, fmt.Errorf("store is down")
+}
+
+func (failingLoginAttemptStore) RemoveFailure(key string) error {
+	return fmt.Errorf("store is down")
+}
+
+func (failingLoginAttemptStore) Reset(key string) error {
+	return fmt.Errorf("store is down")
+}
+
+func expectLoginAudit(mock sqlmock.Sqlmock, reason string) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "login_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg(), sqlmock.AnyArg(), "127.0.0.1", reason).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+}
+
 func expectInsert(mock sqlmock.Sqlmock) {
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT")
@@ -45,7 +68,7 @@ var _ = Describe("Gamemanagement", func() {
 	BeforeEach(func() {
 		var c dataaccess.Connection
 		c, mock = dataaccess.NewMockConnection()
-		gamemanagement = logic.NewGamemanagement(c, da.NewGameRepository(c), da.NewPlayerRepository(c), da.NewPlayerExceptionRepository(c), gl.NewMockRandomizer())
+		gamemanagement = newTestGamemanagement(c, nil, nil)
 	})
 
 	Context("Game", func() {
@@ -65,6 +88,68 @@ var _ = Describe("Gamemanagement", func() {
 			Expect(createGameResponse.Code).NotTo(BeEmpty())
 			Expect(createGameResponse.Code).To(MatchRegexp("[a-zA-Z0-9]{21,22}"))
 		})
+		It("can be created by an organizer who does not take part", func() {
+			createGameTo := NewCreateGameTo()
+			createGameTo.OrganizerOnly = true
+			mock.ExpectBegin()
+			expectInsertGame(mock)
+			mock.ExpectQuery(`INSERT INTO "players"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Martin", "Martin", "martin", sqlmock.AnyArg(), sqlmock.AnyArg(), "Ready", "Organizer", nil, false).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectCommit()
+			_, err := gamemanagement.CreateNewGame(createGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("can not be created with an event date in the past", func() {
+			createGameTo := NewCreateGameTo()
+			eventDate := time.Now().Add(-time.Hour)
+			createGameTo.EventDate = &eventDate
+			_, err := gamemanagement.CreateNewGame(createGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrEventDatePassed))
+		})
+		It("can be linked to the previous game by its admin", func() {
+			createGameTo := NewCreateGameTo()
+			createGameTo.PreviousGameCode = "LASTYEAR"
+			createGameTo.PreviousGamePassword = "Lastyear1"
+			hash, _ := bcrypt.GenerateFromPassword([]byte("Lastyear1"), bcrypt.MinCost)
+			mock.ExpectQuery("SELECT").WithArgs("LASTYEAR").WillReturnRows(sqlmock.NewRows([]string{"id", "code"}).AddRow(7, "LASTYEAR"))
+			mock.ExpectQuery("SELECT").WithArgs("martin", 7).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role"}).AddRow(1, "Martin", hash, "Organizer"))
+			mock.ExpectBegin()
+			mock.ExpectQuery(`INSERT INTO "games"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "ABC", "DEF", sqlmock.AnyArg(), "Created", "Free", false, 7, 0, 1, nil, "", "").WillReturnRows(mock.NewRows([]string{"id"}).AddRow(8))
+			expectInsertPlayer(mock)
+			mock.ExpectCommit()
+			_, err := gamemanagement.CreateNewGame(createGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("can not be linked to a previous game by one of its players", func() {
+			createGameTo := NewCreateGameTo()
+			createGameTo.PreviousGameCode = "LASTYEAR"
+			createGameTo.PreviousGamePassword = "Lastyear1"
+			hash, _ := bcrypt.GenerateFromPassword([]byte("Lastyear1"), bcrypt.MinCost)
+			mock.ExpectQuery("SELECT").WithArgs("LASTYEAR").WillReturnRows(sqlmock.NewRows([]string{"id", "code"}).AddRow(7, "LASTYEAR"))
+			mock.ExpectQuery("SELECT").WithArgs("martin", 7).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role"}).AddRow(1, "Martin", hash, "Player"))
+			_, err := gamemanagement.CreateNewGame(createGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrPreviousGameForbidden))
+		})
+		It("can not be linked to a previous game with a wrong password", func() {
+			createGameTo := NewCreateGameTo()
+			createGameTo.PreviousGameCode = "LASTYEAR"
+			createGameTo.PreviousGamePassword = "Guessed"
+			hash, _ := bcrypt.GenerateFromPassword([]byte("Lastyear1"), bcrypt.MinCost)
+			mock.ExpectQuery("SELECT").WithArgs("LASTYEAR").WillReturnRows(sqlmock.NewRows([]string{"id", "code"}).AddRow(7, "LASTYEAR"))
+			mock.ExpectQuery("SELECT").WithArgs("martin", 7).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role"}).AddRow(1, "Martin", hash, "Admin"))
+			_, err := gamemanagement.CreateNewGame(createGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrPreviousGameForbidden))
+		})
+		It("can not be linked to a previous game without its password", func() {
+			createGameTo := NewCreateGameTo()
+			createGameTo.PreviousGameCode = "LASTYEAR"
+			_, err := gamemanagement.CreateNewGame(createGameTo)
+			Expect(err).To(BeAssignableToTypeOf(validator.ValidationErrors{}))
+		})
 		It("fails to be created with an empty object", func() {
 
 			createGameTo := to.CreateGameTo{}
@@ -136,27 +221,46 @@ Key: 'CreateGameTo.AdminPassword' Error:Field validation for 'AdminPassword' fai
 		})
 		It("should be found as full game with valid information", func() {
 
-			code, title, description, playerName, gifted := "ABC", "GameTitle", "GameDescription", "Max", ""
+			code, title, description, playerName := "ABC", "GameTitle", "GameDescription", "Max"
 			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "title", "description", "status"}).AddRow(1, code, title, description, da.StatusCreated.String()))
-			expectedFullGameResponseTo := to.GetFullGameResponseTo{Code: code, Title: title, Description: description, Status: da.StatusCreated.String(), Gifted: gifted}
+			expectedFullGameResponseTo := to.GetFullGameResponseTo{Code: code, Title: title, Description: description, Status: da.StatusCreated.String(), Gifted: []string{}}
 
 			getFullGameResponseTo, err := gamemanagement.GetFullGameByCode(code, playerName)
 
 			Expect(err).ToNot(HaveOccurred())
-			Expect(getFullGameResponseTo).To(BeIdenticalTo(expectedFullGameResponseTo))
+			Expect(getFullGameResponseTo).To(Equal(expectedFullGameResponseTo))
 		})
 		It("should be found as full game with gifted player in drawn game with valid information", func() {
 
-			code, title, description, playerName, gifted := "ABC", "GameTitle", "GameDescription", "Max", "Moritz"
+			code, title, description, playerName := "ABC", "GameTitle", "GameDescription", "Max"
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "title", "description", "status"}).AddRow(1, code, title, description, da.StatusDrawn.String()))
+			mock.ExpectQuery("SELECT").WithArgs(da.NormalizeName(playerName), 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, playerName))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "giver_id", "receiver_id"}).AddRow(1, 2, 3).AddRow(2, 2, 4))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(2, playerName, playerName))
+			mock.ExpectQuery("SELECT").WithArgs(3, 4).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(3, "Moritz", "Moritz").AddRow(4, "Susi", "Susanne"))
+			expectedFullGameResponseTo := to.GetFullGameResponseTo{Code: code, Title: title, Description: description, Status: da.StatusDrawn.String(), Gifted: []string{"Moritz", "Susanne"}}
+
+			getFullGameResponseTo, err := gamemanagement.GetFullGameByCode(code, playerName)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(getFullGameResponseTo).To(Equal(expectedFullGameResponseTo))
+		})
+		It("should tell only the player that a repair changed his receivers", func() {
+
+			code, title, description, playerName := "ABC", "GameTitle", "GameDescription", "Max"
 			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "title", "description", "status"}).AddRow(1, code, title, description, da.StatusDrawn.String()))
-			mock.ExpectQuery("SELECT").WithArgs(playerName, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "gifted_id"}).AddRow(2, playerName, 3))
-			mock.ExpectQuery("SELECT").WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, gifted))
-			expectedFullGameResponseTo := to.GetFullGameResponseTo{Code: code, Title: title, Description: description, Status: da.StatusDrawn.String(), Gifted: gifted}
+			mock.ExpectQuery("SELECT").WithArgs(da.NormalizeName(playerName), 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "receivers_changed"}).AddRow(2, playerName, true))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "giver_id", "receiver_id"}).AddRow(1, 2, 3))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(2, playerName, playerName))
+			mock.ExpectQuery("SELECT").WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(3, "Moritz", "Moritz"))
 
 			getFullGameResponseTo, err := gamemanagement.GetFullGameByCode(code, playerName)
 
 			Expect(err).ToNot(HaveOccurred())
-			Expect(getFullGameResponseTo).To(BeIdenticalTo(expectedFullGameResponseTo))
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(getFullGameResponseTo.Gifted).To(Equal([]string{"Moritz"}))
+			Expect(getFullGameResponseTo.ReceiversChanged).To(BeTrue())
 		})
 		It("should not be found as full game with empty game code", func() {
 
@@ -164,7 +268,7 @@ Key: 'CreateGameTo.AdminPassword' Error:Field validation for 'AdminPassword' fai
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError("Code must not be empty"))
-			Expect(getFullGameResponseTo).To(BeIdenticalTo(to.GetFullGameResponseTo{}))
+			Expect(getFullGameResponseTo).To(Equal(to.GetFullGameResponseTo{}))
 		})
 		It("should not be found as full game with empty playerName", func() {
 
@@ -172,7 +276,7 @@ Key: 'CreateGameTo.AdminPassword' Error:Field validation for 'AdminPassword' fai
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError("playerName must not be empty"))
-			Expect(getFullGameResponseTo).To(BeIdenticalTo(to.GetFullGameResponseTo{}))
+			Expect(getFullGameResponseTo).To(Equal(to.GetFullGameResponseTo{}))
 		})
 		It("should not be found as full game if game does not exist", func() {
 
@@ -182,19 +286,19 @@ Key: 'CreateGameTo.AdminPassword' Error:Field validation for 'AdminPassword' fai
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(gorm.ErrRecordNotFound))
-			Expect(getFullGameResponseTo).To(BeIdenticalTo(to.GetFullGameResponseTo{}))
+			Expect(getFullGameResponseTo).To(Equal(to.GetFullGameResponseTo{}))
 		})
 		It("should not be found as full game if player does not exist", func() {
 
 			code, title, description, playerName := "ABC", "GameTitle", "GameDescription", "Max"
 			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "title", "description", "status"}).AddRow(1, code, title, description, da.StatusDrawn.String()))
-			mock.ExpectQuery("SELECT").WithArgs(playerName, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "gifted_id"}))
+			mock.ExpectQuery("SELECT").WithArgs(playerName, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
 
 			getFullGameResponseTo, err := gamemanagement.GetFullGameByCode(code, playerName)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError("Player not found"))
-			Expect(getFullGameResponseTo).To(BeIdenticalTo(to.GetFullGameResponseTo{}))
+			Expect(getFullGameResponseTo).To(Equal(to.GetFullGameResponseTo{}))
 		})
 		It("should reset a game", func() {
 			code := "ABC"
@@ -202,8 +306,12 @@ Key: 'CreateGameTo.AdminPassword' Error:Field validation for 'AdminPassword' fai
 			description := "GameDescription"
 			status := "Drawn"
 			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "code", "status"}).AddRow(1, title, description, code, status))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "giver_id", "receiver_id"}))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "number"}))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id"}))
 			mock.ExpectBegin()
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, title, description, code, da.StatusReady.String(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, title, description, code, da.StatusReset.String(), "", false, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(0, 1))
 			mock.ExpectCommit()
 			err := gamemanagement.ResetGame(code)
 			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
@@ -226,19 +334,70 @@ Key: 'CreateGameTo.AdminPassword' Error:Field validation for 'AdminPassword' fai
 			code := "ABC"
 			title := "GameTitle"
 			description := "GameDescription"
-			status := "Drawn"
+			status := "Ready"
 			drawGameTo := to.DrawGameTo{GameCode: code}
 			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "code", "status"}).AddRow(1, title, description, code, status))
 			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready").AddRow(4, "Strolch", 1, "Ready"))
 			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 1, 2))
 			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max"))
 			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Moritz"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(false, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery(`INSERT INTO "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "2", 42, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, title, description, code, "Drawn", "", false, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawGameResponseTo.Ok).To(BeTrue())
+			Expect(drawGameResponseTo.Message).To(BeEmpty())
+		})
+		It("should draw a game with exactly one valid assignment", func() {
+			code := "ABC"
+			drawGameTo := to.DrawGameTo{GameCode: code}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 1, 3).AddRow(2, 2, 1).AddRow(3, 3, 2))
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz").AddRow(3, "Susi"))
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz").AddRow(3, "Susi"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(false, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 1, 2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, 3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 3, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery(`INSERT INTO "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "2", 42, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", code, "Drawn", "", false, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawGameResponseTo.Ok).To(BeTrue())
+		})
+		It("should not repeat the pairings of last year", func() {
+			code := "ABC"
+			drawGameTo := to.DrawGameTo{GameCode: code}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "previous_game_id", "avoid_repeat_years"}).AddRow(2, code, "Ready", 1, 1))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(4, "Max", 2, "Ready").AddRow(5, "Moritz", 2, "Ready").AddRow(6, "Susi", 2, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, "XYZ", "Drawn"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1).AddRow(2, "Moritz", 1).AddRow(3, "Susi", 1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "giver_id", "receiver_id"}).AddRow(1, 1, 1, 2).AddRow(2, 1, 2, 3).AddRow(3, 1, 3, 1))
 			mock.ExpectBegin()
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg(), "", 1, "Ready", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg(), "", 1, "Ready", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg(), "", 1, "Ready", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg(), "", 1, "Ready", "", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, title, description, code, "Drawn", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 2).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 2, 4, 6).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 2, 5, 4).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 2, 6, 5).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery(`INSERT INTO "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 2, "2", 42, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", code, "Drawn", "", false, 1, 1, 0, nil, "", "", 2).WillReturnResult(sqlmock.NewResult(1, 1))
 			mock.ExpectCommit()
 			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
 			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
@@ -246,21 +405,230 @@ Key: 'CreateGameTo.AdminPassword' Error:Field validation for 'AdminPassword' fai
 			Expect(drawGameResponseTo.Ok).To(BeTrue())
 			Expect(drawGameResponseTo.Message).To(BeEmpty())
 		})
+		It("should allow pairings of previous years again if they can not be avoided", func() {
+			code := "ABC"
+			drawGameTo := to.DrawGameTo{GameCode: code}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "previous_game_id", "avoid_repeat_years"}).AddRow(2, code, "Ready", 1, 3))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(3, "Max", 2, "Ready").AddRow(4, "Moritz", 2, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, "XYZ", "Drawn"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1).AddRow(2, "Moritz", 1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "giver_id", "receiver_id"}).AddRow(1, 1, 1, 2).AddRow(2, 1, 2, 1))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 2).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 2, 3, 4).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 2, 4, 3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery(`INSERT INTO "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 2, "2", 42, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", code, "Drawn", "", false, 1, 3, 0, nil, "", "", 2).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawGameResponseTo.Ok).To(BeTrue())
+			Expect(drawGameResponseTo.Message).To(Equal("Paarungen aus den Vorjahren ließen sich nicht vermeiden und wurden wieder zugelassen."))
+		})
+		It("should draw a game as a single cycle", func() {
+			code := "ABC"
+			drawGameTo := to.DrawGameTo{GameCode: code}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "draw_mode"}).AddRow(1, code, "Ready", "SingleCycle"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 1, 2))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max"))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Moritz"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(false, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 1, 3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 3, 2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery(`INSERT INTO "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "2", 42, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", code, "Drawn", "SingleCycle", false, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawGameResponseTo.Ok).To(BeTrue())
+		})
+		It("should fail to draw a single cycle if the exceptions split the players", func() {
+			code := "ABC"
+			drawGameTo := to.DrawGameTo{GameCode: code}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "draw_mode"}).AddRow(1, code, "Ready", "SingleCycle"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz").AddRow(3, "Susi").AddRow(4, "Strolch"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 1, 3).AddRow(2, 1, 4).AddRow(3, 2, 3).AddRow(4, 2, 4))
+			mock.ExpectQuery("SELECT").WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz"))
+			mock.ExpectQuery("SELECT").WithArgs(3, 4).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Susi").AddRow(4, "Strolch"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawGameResponseTo.Ok).To(BeFalse())
+			Expect(drawGameResponseTo.Message).To(BeIdenticalTo("Mit den definierten Ausnahmen ist keine Auslosung in einem einzigen Kreis möglich. Bitte weniger Ausnahmen definieren oder den Modus ändern."))
+		})
+		It("should leave the organizer out of the draw", func() {
+			code := "ABC"
+			drawGameTo := to.DrawGameTo{GameCode: code}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status", "role"}).AddRow(4, "Chefin", 1, "Ready", "Organizer").AddRow(1, "Max", 1, "Ready", "Player").AddRow(2, "Moritz", 1, "Ready", "Player").AddRow(3, "Susi", 1, "Ready", "Player"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 1, 3).AddRow(2, 2, 1).AddRow(3, 3, 2))
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz").AddRow(3, "Susi"))
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz").AddRow(3, "Susi"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(false, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 1, 2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, 3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 3, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+			mock.ExpectQuery(`INSERT INTO "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "2", 42, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", code, "Drawn", "", false, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawGameResponseTo.Ok).To(BeTrue())
+		})
+		It("should fail to draw a game without mutual pairs for two players", func() {
+			code := "ABC"
+			drawGameTo := to.DrawGameTo{GameCode: code}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "forbid_mutual_pairs"}).AddRow(1, code, "Ready", true))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawGameResponseTo.Ok).To(BeFalse())
+			Expect(drawGameResponseTo.Message).To(BeIdenticalTo("Mit den definierten Ausnahmen ist keine Auslosung ohne gegenseitiges Beschenken möglich. Bitte weniger Ausnahmen definieren oder gegenseitiges Beschenken erlauben."))
+		})
+		It("should draw a game without mutual pairs", func() {
+			code := "ABC"
+			drawGameTo := to.DrawGameTo{GameCode: code}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "forbid_mutual_pairs"}).AddRow(1, code, "Ready", true))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready").AddRow(4, "Strolch", 1, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 1, 2))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max"))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Moritz"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(false, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			for i := 0; i < 4; i++ {
+				mock.ExpectQuery("INSERT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(i + 1))
+			}
+			mock.ExpectQuery(`INSERT INTO "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "2", 42, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", code, "Drawn", "", true, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawGameResponseTo.Ok).To(BeTrue())
+		})
+		It("should draw several giftees per player", func() {
+			code := "ABC"
+			drawGameTo := to.DrawGameTo{GameCode: code}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "gifts_per_player"}).AddRow(1, code, "Ready", 2))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready").AddRow(4, "Strolch", 1, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(false, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			for _, giver := range []int{1, 1, 2, 2, 3, 3, 4, 4} {
+				mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, giver, sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			}
+			mock.ExpectQuery(`INSERT INTO "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "2", 42, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", code, "Drawn", "", false, nil, 0, 2, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawGameResponseTo.Ok).To(BeTrue())
+		})
+		It("should fail to draw several giftees per player if there are not enough players", func() {
+			code := "ABC"
+			drawGameTo := to.DrawGameTo{GameCode: code}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "gifts_per_player"}).AddRow(1, code, "Ready", 3))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawGameResponseTo.Ok).To(BeFalse())
+			Expect(drawGameResponseTo.Message).To(Equal("Mit den definierten Ausnahmen kann nicht jede Person 3 Personen beschenken und von 3 Personen beschenkt werden. Bitte weniger Ausnahmen oder weniger Geschenke pro Person definieren."))
+		})
+		It("should update the draw settings of a game", func() {
+			code := "ABC"
+			drawMode, forbidMutualPairs := da.DrawModeSingleCycle.String(), true
+			updateGameSettingsTo := to.UpdateGameSettingsTo{GameCode: code, DrawMode: &drawMode, ForbidMutualPairs: &forbidMutualPairs}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "title", "code", "status", "draw_mode"}).AddRow(1, "Title", code, "Ready", "Free"))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Title", "", code, "Ready", drawMode, true, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := gamemanagement.UpdateGameSettings(updateGameSettingsTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should update the details of a drawn game", func() {
+			code := "ABC"
+			title, description, budget := "Wichteln 2026", "Im Büro", "20 €"
+			updateGameSettingsTo := to.UpdateGameSettingsTo{GameCode: code, Title: &title, Description: &description, Budget: &budget}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "title", "code", "status"}).AddRow(1, "Title", code, "Drawn"))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, title, description, code, "Drawn", "", false, nil, 0, 0, nil, "", budget, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := gamemanagement.UpdateGameSettings(updateGameSettingsTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should not change the draw options together with the details of a drawn game", func() {
+			code := "ABC"
+			title, giftsPerPlayer := "Wichteln 2026", 2
+			updateGameSettingsTo := to.UpdateGameSettingsTo{GameCode: code, Title: &title, GiftsPerPlayer: &giftsPerPlayer}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "title", "code", "status"}).AddRow(1, "Title", code, "Drawn"))
+			err := gamemanagement.UpdateGameSettings(updateGameSettingsTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrGameAlreadyDrawn))
+		})
+		It("should not link a game as its own previous game", func() {
+			code := "ABC"
+			updateGameSettingsTo := to.UpdateGameSettingsTo{GameCode: code, PlayerName: "Martin", PreviousGameCode: &code, PreviousGamePassword: "Test12345"}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "title", "code", "status"}).AddRow(1, "Title", code, "Ready"))
+			err := gamemanagement.UpdateGameSettings(updateGameSettingsTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrInvalidPreviousGame))
+		})
+		It("should fail to update a game with an empty title", func() {
+			title := ""
+			updateGameSettingsTo := to.UpdateGameSettingsTo{GameCode: "ABC", Title: &title}
+			err := gamemanagement.UpdateGameSettings(updateGameSettingsTo)
+			Expect(err).To(BeAssignableToTypeOf(validator.ValidationErrors{}))
+		})
+		It("should fail to update the draw settings with an unknown draw mode", func() {
+			drawMode := "Chaos"
+			updateGameSettingsTo := to.UpdateGameSettingsTo{GameCode: "ABC", DrawMode: &drawMode}
+			err := gamemanagement.UpdateGameSettings(updateGameSettingsTo)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(validator.ValidationErrors{}))
+		})
 		It("should fail to draw a game with too many exceptions", func() {
 			code := "ABC"
 			title := "GameTitle"
 			description := "GameDescription"
-			status := "Drawn"
+			status := "Ready"
 			drawGameTo := to.DrawGameTo{GameCode: code}
 			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "code", "status"}).AddRow(1, title, description, code, status))
 			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz"))
 			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 1, 2))
 			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max"))
 			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Moritz"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
 			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(drawGameResponseTo.Ok).To(BeFalse())
-			Expect(drawGameResponseTo.Message).To(BeIdenticalTo("Nach 100 Versuchen wurde kein plausibles Ergebnis gefunden. Bitte nochmal versuchen oder weniger Ausnahmen definieren."))
+			Expect(drawGameResponseTo.Message).To(BeIdenticalTo("Mit den definierten Ausnahmen ist keine Auslosung möglich. Bitte weniger Ausnahmen definieren."))
 		})
 		It("should fail to draw a game with empty code", func() {
 			code := ""
@@ -284,7 +652,7 @@ Key: 'CreateGameTo.AdminPassword' Error:Field validation for 'AdminPassword' fai
 		It("should fail to draw a game if players can't be received", func() {
 			code := "ABC"
 			drawGameTo := to.DrawGameTo{GameCode: code}
-			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Ready"))
 			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnError(gorm.ErrInvalidData)
 			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
 			Expect(err).To(HaveOccurred())
@@ -295,7 +663,7 @@ Key: 'CreateGameTo.AdminPassword' Error:Field validation for 'AdminPassword' fai
 		It("should fail to draw a game if exceptions can't be received", func() {
 			code := "ABC"
 			drawGameTo := to.DrawGameTo{GameCode: code}
-			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Ready"))
 			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz"))
 			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnError(gorm.ErrInvalidData)
 			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
@@ -309,17 +677,77 @@ Key: 'CreateGameTo.AdminPassword' Error:Field validation for 'AdminPassword' fai
 		It("can be added to a game with valid information", func() {
 			addRemovePlayerTo := to.AddRemovePlayerTo{Name: "Max", GameCode: "ABC"}
 			mock.ExpectQuery("SELECT").WithArgs(addRemovePlayerTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(2, "Erika", "Erika"))
 			mock.ExpectBegin()
-			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", "", 1, "", "Player", nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", "", "Waiting", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", "Max", "max", "", 1, "", "Player", nil, false).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec(`DELETE FROM "invites" WHERE player_id = \$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`INSERT INTO "invites"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", "", "Waiting", "", false, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(1, 1))
 			mock.ExpectCommit()
-			err := gamemanagement.AddPlayerToGame(addRemovePlayerTo)
+			inviteTo, err := gamemanagement.AddPlayerToGame(addRemovePlayerTo)
 			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
 			Expect(err).ToNot(HaveOccurred())
+			Expect(inviteTo.Name).To(Equal("Max"))
+			Expect(inviteTo.Token).ToNot(BeEmpty())
+		})
+		It("should not be added twice with a different spelling", func() {
+			addRemovePlayerTo := to.AddRemovePlayerTo{Name: "jürgen", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs(addRemovePlayerTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(1, "Jürgen", "Jürgen"))
+			_, err := gamemanagement.AddPlayerToGame(addRemovePlayerTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrPlayerAlreadyExists))
+		})
+		It("should not be added with the name another player is shown with", func() {
+			addRemovePlayerTo := to.AddRemovePlayerTo{Name: " Chef ", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs(addRemovePlayerTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(1, "Max", "chef"))
+			_, err := gamemanagement.AddPlayerToGame(addRemovePlayerTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrPlayerAlreadyExists))
+		})
+		It("should match names regardless of case and Unicode composition", func() {
+			Expect(da.NormalizeName("Jürgen")).To(Equal(da.NormalizeName("jürgen")))
+			Expect(da.NormalizeName("Ju\u0308rgen")).To(Equal(da.NormalizeName("J\u00fcrgen")))
+			Expect(da.NormalizeName(" Max ")).To(Equal("max"))
+			Expect(da.NormalizeName("Max")).ToNot(Equal(da.NormalizeName("Moritz")))
+		})
+		It("should be renamed without changing the login name", func() {
+			renamePlayerTo := to.RenamePlayerTo{Name: "max", DisplayName: "Maximilian", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs(renamePlayerTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Drawn"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name", "game_id", "status", "role"}).AddRow(1, "Max", "Max", 1, "Ready", "Player").AddRow(2, "Moritz", "Moritz", 1, "Ready", "Player"))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", "Maximilian", "max", "", 1, "Ready", "Player", nil, false, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := gamemanagement.RenamePlayer(renamePlayerTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should not be renamed to the name of another player", func() {
+			renamePlayerTo := to.RenamePlayerTo{Name: "Max", DisplayName: "MORITZ", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs(renamePlayerTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(1, "Max", "Max").AddRow(2, "Moritz", "Moritz"))
+			err := gamemanagement.RenamePlayer(renamePlayerTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrPlayerAlreadyExists))
+		})
+		It("should not be renamed to a name of only spaces", func() {
+			renamePlayerTo := to.RenamePlayerTo{Name: "Max", DisplayName: "   ", GameCode: "ABC"}
+			err := gamemanagement.RenamePlayer(renamePlayerTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			_, ok := err.(validator.ValidationErrors)
+			Expect(ok).To(BeTrue())
+		})
+		It("should fail to rename a player who does not exist", func() {
+			renamePlayerTo := to.RenamePlayerTo{Name: "Susi", DisplayName: "Susanne", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs(renamePlayerTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(1, "Max", "Max"))
+			err := gamemanagement.RenamePlayer(renamePlayerTo)
+			Expect(err).To(MatchError(errors.ErrPlayerNotFound))
 		})
 		It("failes to be added to a game with empty information", func() {
 			addRemovePlayerTo := to.AddRemovePlayerTo{}
-			err := gamemanagement.AddPlayerToGame(addRemovePlayerTo)
+			_, err := gamemanagement.AddPlayerToGame(addRemovePlayerTo)
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(BeAssignableToTypeOf(validator.ValidationErrors{}))
 			valErr := err.(validator.ValidationErrors)
@@ -328,7 +756,7 @@ Key: 'AddRemovePlayerTo.GameCode' Error:Field validation for 'GameCode' failed o
 		})
 		It("failes to be added to a game with no game code", func() {
 			addRemovePlayerTo := to.AddRemovePlayerTo{Name: "Max"}
-			err := gamemanagement.AddPlayerToGame(addRemovePlayerTo)
+			_, err := gamemanagement.AddPlayerToGame(addRemovePlayerTo)
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(BeAssignableToTypeOf(validator.ValidationErrors{}))
 			valErr := err.(validator.ValidationErrors)
@@ -337,7 +765,7 @@ Key: 'AddRemovePlayerTo.GameCode' Error:Field validation for 'GameCode' failed o
 		It("failes to be added to a game which does not exist", func() {
 			addRemovePlayerTo := to.AddRemovePlayerTo{Name: "Max", GameCode: "ABC"}
 			expectDefaultQueryWithNoResult(mock)
-			err := gamemanagement.AddPlayerToGame(addRemovePlayerTo)
+			_, err := gamemanagement.AddPlayerToGame(addRemovePlayerTo)
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError("record not found"))
 		})
@@ -347,11 +775,12 @@ Key: 'AddRemovePlayerTo.GameCode' Error:Field validation for 'GameCode' failed o
 			expectDefaultQuery(mock)
 			mock.ExpectBegin()
 			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), "Max", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), "max", 1).WillReturnResult(sqlmock.NewResult(0, 1))
 			expectDefaultQuery(mock)
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil)
 			mock.ExpectCommit()
 			err := gamemanagement.RemovePlayerFromGame(addRemovePlayerTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
 			Expect(err).ToNot(HaveOccurred())
 		})
 		It("failes to be removed from a game with empty information", func() {
@@ -387,24 +816,28 @@ Key: 'AddRemovePlayerTo.GameCode' Error:Field validation for 'GameCode' failed o
 			Expect(err).To(MatchError("record not found"))
 		})
 		It("should register itself with new credentials", func() {
-			registerLoginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{Name: "Max", Password: "Pass", GameCode: "ABC"}
+			registerLoginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{Name: "Max", Password: "Pass", GameCode: "ABC", InviteToken: "Token"}
 			mock.ExpectQuery("SELECT").WithArgs(registerLoginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Waiting"))
-			mock.ExpectQuery("SELECT").WithArgs(registerLoginPlayerPasswordTo.Name, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role", "game_id"}).AddRow(1, "Max", "", "Player", 1))
+			mock.ExpectQuery("SELECT").WithArgs(da.NormalizeName(registerLoginPlayerPasswordTo.Name), 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role", "game_id"}).AddRow(1, "Max", "", "Player", 1))
 			mock.ExpectBegin()
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", sqlmock.AnyArg(), 1, "Ready", "Player", nil, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(`DELETE FROM "invites"`).WithArgs(sqlmock.AnyArg(), 1, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", "Max", "max", sqlmock.AnyArg(), 1, "Ready", "Player", nil, false, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(`DELETE FROM "invites" WHERE player_id = \$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
 			mock.ExpectQuery("SELECT").WithArgs(1, "Ready").WillReturnRows(sqlmock.NewRows([]string{"id"}))
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", "", "Ready", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", "", "Ready", "", false, nil, 0, 0, nil, "", "", 1).WillReturnResult(sqlmock.NewResult(0, 1))
 			mock.ExpectCommit()
 			err := gamemanagement.RegisterPlayerPassword(registerLoginPlayerPasswordTo)
 			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
 			Expect(err).ToNot(HaveOccurred())
 		})
 		It("should fail to register itself if game update fails", func() {
-			registerLoginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{Name: "Max", Password: "Pass", GameCode: "ABC"}
+			registerLoginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{Name: "Max", Password: "Pass", GameCode: "ABC", InviteToken: "Token"}
 			mock.ExpectQuery("SELECT").WithArgs(registerLoginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Waiting"))
-			mock.ExpectQuery("SELECT").WithArgs(registerLoginPlayerPasswordTo.Name, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role", "game_id"}).AddRow(1, "Max", "", "Player", 1))
+			mock.ExpectQuery("SELECT").WithArgs(da.NormalizeName(registerLoginPlayerPasswordTo.Name), 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role", "game_id"}).AddRow(1, "Max", "", "Player", 1))
 			mock.ExpectBegin()
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", sqlmock.AnyArg(), 1, "Ready", "Player", nil, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(`DELETE FROM "invites"`).WithArgs(sqlmock.AnyArg(), 1, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", "Max", "max", sqlmock.AnyArg(), 1, "Ready", "Player", nil, false, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(`DELETE FROM "invites" WHERE player_id = \$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
 			mock.ExpectQuery("SELECT").WithArgs(1, "Ready").WillReturnError(gorm.ErrInvalidData)
 			mock.ExpectCommit()
 			err := gamemanagement.RegisterPlayerPassword(registerLoginPlayerPasswordTo)
@@ -421,6 +854,79 @@ Key: 'AddRemovePlayerTo.GameCode' Error:Field validation for 'GameCode' failed o
 Key: 'RegisterLoginPlayerPasswordTo.Name' Error:Field validation for 'Name' failed on the 'required' tag
 Key: 'RegisterLoginPlayerPasswordTo.Password' Error:Field validation for 'Password' failed on the 'required' tag`))
 		})
+		It("should not register without the token of an invite", func() {
+			registerLoginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{Name: "Max", Password: "Pass", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs(registerLoginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Waiting"))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role", "game_id"}).AddRow(1, "Max", "", "Player", 1))
+			err := gamemanagement.RegisterPlayerPassword(registerLoginPlayerPasswordTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrInvalidInvite))
+		})
+		It("should not register with an invite that is used, expired or of another player", func() {
+			registerLoginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{Name: "Max", Password: "Pass", GameCode: "ABC", InviteToken: "Token"}
+			mock.ExpectQuery("SELECT").WithArgs(registerLoginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Waiting"))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role", "game_id"}).AddRow(1, "Max", "", "Player", 1))
+			mock.ExpectBegin()
+			mock.ExpectExec(`DELETE FROM "invites" WHERE token_hash = \$1 AND player_id = \$2 AND expires_at > \$3 AND deleted_at IS NULL`).WithArgs(sqlmock.AnyArg(), 1, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+			err := gamemanagement.RegisterPlayerPassword(registerLoginPlayerPasswordTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrInvalidInvite))
+		})
+		It("should issue a new invite and revoke the old ones", func() {
+			invitePlayerTo := to.InvitePlayerTo{Name: "Max", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs(invitePlayerTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1))
+			mock.ExpectBegin()
+			mock.ExpectExec(`DELETE FROM "invites" WHERE player_id = \$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(`INSERT INTO "invites"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectCommit()
+			inviteTo, err := gamemanagement.IssueInvite(invitePlayerTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(inviteTo.Name).To(Equal("Max"))
+			Expect(inviteTo.Token).To(HaveLen(43))
+			Expect(inviteTo.ExpiresAt).To(BeTemporally(">", time.Now()))
+		})
+		It("should not invite a player who has already registered", func() {
+			invitePlayerTo := to.InvitePlayerTo{Name: "Max", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs(invitePlayerTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "game_id"}).AddRow(1, "Max", "secret", 1))
+			_, err := gamemanagement.IssueInvite(invitePlayerTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrPlayerAlreadyRegistered))
+		})
+		It("should revoke the invites of a player", func() {
+			invitePlayerTo := to.InvitePlayerTo{Name: "Max", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs(invitePlayerTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1))
+			mock.ExpectBegin()
+			mock.ExpectExec(`DELETE FROM "invites" WHERE player_id = \$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := gamemanagement.RevokeInvite(invitePlayerTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should not register with a revoked invite", func() {
+			invitePlayerTo := to.InvitePlayerTo{Name: "Max", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs(invitePlayerTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1))
+			mock.ExpectBegin()
+			mock.ExpectExec(`DELETE FROM "invites" WHERE player_id = \$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			registerLoginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{Name: "Max", Password: "Pass", GameCode: "ABC", InviteToken: "Token"}
+			mock.ExpectQuery("SELECT").WithArgs(registerLoginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Waiting"))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role", "game_id"}).AddRow(1, "Max", "", "Player", 1))
+			mock.ExpectBegin()
+			// the revoked invite is gone, and an invite only marked as deleted by an older version does not count either
+			mock.ExpectExec(`DELETE FROM "invites" WHERE token_hash = \$1 AND player_id = \$2 AND expires_at > \$3 AND deleted_at IS NULL`).WithArgs(sqlmock.AnyArg(), 1, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+			err := gamemanagement.RevokeInvite(invitePlayerTo)
+			Expect(err).ToNot(HaveOccurred())
+			err = gamemanagement.RegisterPlayerPassword(registerLoginPlayerPasswordTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrInvalidInvite))
+		})
 		It("should fail to register itself because the game does not exist", func() {
 			registerLoginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{Name: "Max", Password: "Pass", GameCode: "ABC"}
 			expectDefaultQueryWithNoResult(mock)
@@ -483,7 +989,7 @@ Key: 'RegisterLoginPlayerPasswordTo.Password' Error:Field validation for 'Passwo
 			code := "ABC"
 			name := "Max"
 			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-			mock.ExpectQuery("SELECT").WithArgs(name, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "role"}).AddRow(1, "Max", "Admin"))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "role"}).AddRow(1, "Max", "Admin"))
 			role, err := gamemanagement.GetPlayerRoleByCodeAndName(code, name)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(role).To(BeIdenticalTo(da.RoleAdmin.String()))
@@ -517,7 +1023,7 @@ Key: 'RegisterLoginPlayerPasswordTo.Password' Error:Field validation for 'Passwo
 			code := "ABC"
 			name := "NotExistingPlayer"
 			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-			mock.ExpectQuery("SELECT").WithArgs(name, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery("SELECT").WithArgs(da.NormalizeName(name), 1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
 			role, err := gamemanagement.GetPlayerRoleByCodeAndName(code, name)
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(gorm.ErrRecordNotFound))
@@ -529,73 +1035,350 @@ Key: 'RegisterLoginPlayerPasswordTo.Password' Error:Field validation for 'Passwo
 			hash, _ := bcrypt.GenerateFromPassword(plainPasswordByte, bcrypt.DefaultCost)
 			expectedLoginPlayerPasswordResponseTo := to.RegisterLoginPlayerPasswordResponseTo{Ok: true}
 			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.Name, 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}).AddRow("Max", hash))
-			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo)
+			mock.ExpectQuery("SELECT").WithArgs(da.NormalizeName(loginPlayerPasswordTo.Name), 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}).AddRow("Max", hash))
+			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
 			Expect(loginPlayerPasswordResponseTo).To(BeIdenticalTo(expectedLoginPlayerPasswordResponseTo))
 		})
-		It("should register a player if he has no password set", func() {
+		It("should not login a player who has not registered with the invite yet", func() {
 			loginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{GameCode: "ABC", Name: "Max", Password: "12345"}
-			expectedLoginPlayerPasswordResponseTo := to.RegisterLoginPlayerPasswordResponseTo{Ok: true}
-			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Waiting"))
-			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.Name, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role", "game_id"}).AddRow(1, "Max", "", "Player", 1))
 			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Waiting"))
-			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.Name, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role", "game_id"}).AddRow(1, "Max", "", "Player", 1))
-			mock.ExpectBegin()
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", sqlmock.AnyArg(), 1, "Ready", "Player", nil, 1).WillReturnResult(sqlmock.NewResult(1, 1))
-			mock.ExpectQuery("SELECT").WithArgs(1, "Ready").WillReturnRows(sqlmock.NewRows([]string{"id"}))
-			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", "", "Ready", 1).WillReturnResult(sqlmock.NewResult(0, 1))
-			mock.ExpectCommit()
-			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo)
-			Expect(loginPlayerPasswordResponseTo).To(BeIdenticalTo(expectedLoginPlayerPasswordResponseTo))
+			mock.ExpectQuery("SELECT").WithArgs(da.NormalizeName(loginPlayerPasswordTo.Name), 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "password", "role", "game_id"}).AddRow(1, "Max", "", "Player", 1))
+			expectLoginAudit(mock, "notRegistered")
+			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(loginPlayerPasswordResponseTo.Ok).To(BeFalse())
+			Expect(loginPlayerPasswordResponseTo.Message).To(Equal("Bitte registriere dich zuerst über deinen Einladungslink"))
 		})
 		It("should not login a player if input is invalid", func() {
 			loginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{GameCode: "", Name: "", Password: ""}
-			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo)
+			expectLoginAudit(mock, "invalid")
+			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
 			expectedLoginPlayerPasswordResponseTo := to.RegisterLoginPlayerPasswordResponseTo{Ok: false, Message: "Falsche Game-ID, falscher Nutzername oder falsches Passwort"}
 			Expect(loginPlayerPasswordResponseTo).To(BeIdenticalTo(expectedLoginPlayerPasswordResponseTo))
 		})
 		It("should not login a player if game is not found", func() {
 			loginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{GameCode: "NotFound", Name: "Max", Password: "12345"}
 			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}))
-			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo)
+			expectLoginAudit(mock, "unknownGame")
+			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
 			expectedLoginPlayerPasswordResponseTo := to.RegisterLoginPlayerPasswordResponseTo{Ok: false, Message: "Falsche Game-ID, falscher Nutzername oder falsches Passwort"}
 			Expect(loginPlayerPasswordResponseTo).To(BeIdenticalTo(expectedLoginPlayerPasswordResponseTo))
 		})
 		It("should not login a player if player is not found", func() {
 			loginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{GameCode: "NotFound", Name: "Max", Password: "12345"}
 			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.Name, 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}))
-			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo)
+			mock.ExpectQuery("SELECT").WithArgs(da.NormalizeName(loginPlayerPasswordTo.Name), 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}))
+			expectLoginAudit(mock, "unknownPlayer")
+			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
 			expectedLoginPlayerPasswordResponseTo := to.RegisterLoginPlayerPasswordResponseTo{Ok: false, Message: "Falsche Game-ID, falscher Nutzername oder falsches Passwort"}
 			Expect(loginPlayerPasswordResponseTo).To(BeIdenticalTo(expectedLoginPlayerPasswordResponseTo))
 		})
 		It("should not login a player if password is wrong", func() {
 			loginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{GameCode: "NotFound", Name: "Max", Password: "12345"}
 			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.Name, 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}).AddRow("Max", "WrongHash"))
-			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo)
+			mock.ExpectQuery("SELECT").WithArgs(da.NormalizeName(loginPlayerPasswordTo.Name), 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}).AddRow("Max", "WrongHash"))
+			expectLoginAudit(mock, "wrongPassword")
+			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
 			expectedLoginPlayerPasswordResponseTo := to.RegisterLoginPlayerPasswordResponseTo{Ok: false, Message: "Falsche Game-ID, falscher Nutzername oder falsches Passwort"}
 			Expect(loginPlayerPasswordResponseTo).To(BeIdenticalTo(expectedLoginPlayerPasswordResponseTo))
 		})
+		It("should make a player wait after a few wrong passwords", func() {
+			loginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{GameCode: "ABC", Name: "Max", Password: "12345"}
+			for i := 0; i < 4; i++ {
+				mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}).AddRow("Max", "WrongHash"))
+				expectLoginAudit(mock, "wrongPassword")
+				loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
+				Expect(loginPlayerPasswordResponseTo.RetryAfter).To(BeZero())
+			}
+			expectLoginAudit(mock, "locked")
+			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(loginPlayerPasswordResponseTo.Ok).To(BeFalse())
+			Expect(loginPlayerPasswordResponseTo.RetryAfter).To(Equal(1))
+			Expect(loginPlayerPasswordResponseTo.Message).To(Equal("Zu viele Fehlversuche, bitte warte etwas und versuche es dann erneut"))
+		})
+		It("should only slow down wrong passwords of a player from different clients", func() {
+			store := logic.NewMemoryLoginAttemptStore()
+			var c dataaccess.Connection
+			c, mock = dataaccess.NewMockConnection()
+			gamemanagement = newTestGamemanagement(c, store, nil)
+			for i := 0; i < 10; i++ {
+				_, err := store.AddFailure("player:ABC:max", time.Now(), time.Now().Add(-time.Hour))
+				Expect(err).ToNot(HaveOccurred())
+			}
+			expectLoginAudit(mock, "locked")
+			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(to.RegisterLoginPlayerPasswordTo{GameCode: "ABC", Name: "MAX ", Password: "12345"}, "127.0.0.1")
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(loginPlayerPasswordResponseTo.RetryAfter).To(BeNumerically("~", 64, 1))
+		})
+		It("should lock only the client which guessed the password of a player too often", func() {
+			store := logic.NewMemoryLoginAttemptStore()
+			var c dataaccess.Connection
+			c, mock = dataaccess.NewMockConnection()
+			gamemanagement = newTestGamemanagement(c, store, nil)
+			for i := 0; i < 10; i++ {
+				_, err := store.AddFailure("player:ABC:max:127.0.0.1", time.Now(), time.Now().Add(-time.Hour))
+				Expect(err).ToNot(HaveOccurred())
+			}
+			expectLoginAudit(mock, "locked")
+			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(to.RegisterLoginPlayerPasswordTo{GameCode: "ABC", Name: "Max", Password: "12345"}, "127.0.0.1")
+			Expect(loginPlayerPasswordResponseTo.RetryAfter).To(BeNumerically("~", 3600, 1))
+			hash, _ := bcrypt.GenerateFromPassword([]byte("12345"), bcrypt.MinCost)
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}).AddRow("Max", hash))
+			loginPlayerPasswordResponseTo = gamemanagement.LoginPlayer(to.RegisterLoginPlayerPasswordTo{GameCode: "ABC", Name: "Max", Password: "12345"}, "10.0.0.1")
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(loginPlayerPasswordResponseTo.Ok).To(BeTrue())
+		})
+		It("should reject the login if the failed logins can not be counted", func() {
+			var c dataaccess.Connection
+			c, mock = dataaccess.NewMockConnection()
+			gamemanagement = newTestGamemanagement(c, failingLoginAttemptStore{}, nil)
+			expectLoginAudit(mock, "throttleUnavailable")
+			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(to.RegisterLoginPlayerPasswordTo{GameCode: "ABC", Name: "Max", Password: "12345"}, "127.0.0.1")
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(loginPlayerPasswordResponseTo.Ok).To(BeFalse())
+			Expect(loginPlayerPasswordResponseTo.Message).To(Equal("Die Anmeldung ist gerade nicht möglich, bitte versuche es später erneut"))
+		})
+		It("should let only the free logins check the password when they run concurrently", func() {
+			var c dataaccess.Connection
+			c, mock = dataaccess.NewMockConnection()
+			mock.MatchExpectationsInOrder(false)
+			gamemanagement = newTestGamemanagement(c, logic.NewMemoryLoginAttemptStore(), nil)
+			loginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{GameCode: "ABC", Name: "Max", Password: "12345"}
+			logins := 20
+			for i := 0; i < 4; i++ {
+				mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}).AddRow("Max", "WrongHash"))
+				expectLoginAudit(mock, "wrongPassword")
+			}
+			for i := 4; i < logins; i++ {
+				expectLoginAudit(mock, "locked")
+			}
+			responses := make(chan to.RegisterLoginPlayerPasswordResponseTo, logins)
+			var wait sync.WaitGroup
+			for i := 0; i < logins; i++ {
+				wait.Add(1)
+				go func() {
+					defer wait.Done()
+					responses <- gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
+				}()
+			}
+			wait.Wait()
+			close(responses)
+			checked := 0
+			for loginPlayerPasswordResponseTo := range responses {
+				Expect(loginPlayerPasswordResponseTo.Ok).To(BeFalse())
+				if loginPlayerPasswordResponseTo.RetryAfter == 0 {
+					checked++
+				}
+			}
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(checked).To(Equal(4))
+		})
+		It("should forget the wrong passwords of a player after a successful login", func() {
+			loginPlayerPasswordTo := to.RegisterLoginPlayerPasswordTo{GameCode: "ABC", Name: "Max", Password: "12345"}
+			hash, _ := bcrypt.GenerateFromPassword([]byte(loginPlayerPasswordTo.Password), bcrypt.MinCost)
+			for i := 0; i < 3; i++ {
+				mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}).AddRow("Max", "WrongHash"))
+				expectLoginAudit(mock, "wrongPassword")
+				gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
+			}
+			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}).AddRow("Max", hash))
+			Expect(gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1").Ok).To(BeTrue())
+			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}).AddRow("Max", "WrongHash"))
+			expectLoginAudit(mock, "wrongPassword")
+			gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
+			mock.ExpectQuery("SELECT").WithArgs(loginPlayerPasswordTo.GameCode).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"name", "password"}).AddRow("Max", hash))
+			loginPlayerPasswordResponseTo := gamemanagement.LoginPlayer(loginPlayerPasswordTo, "127.0.0.1")
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(loginPlayerPasswordResponseTo.Ok).To(BeTrue())
+		})
+		It("should count the failed logins in the database for several instances", func() {
+			var c dataaccess.Connection
+			c, mock = dataaccess.NewMockConnection()
+			store := logic.NewDatabaseLoginAttemptStore(da.NewLoginAttemptRepository(c))
+			now := time.Now()
+			previous := now.Add(-time.Minute)
+			mock.ExpectQuery(`INSERT INTO login_attempts .* ON CONFLICT \(throttle_key\) DO UPDATE SET failures = CASE WHEN login_attempts.last_failure < \$4 THEN 1 ELSE login_attempts.failures \+ 1 END, previous_failure = login_attempts.last_failure, last_failure = excluded.last_failure RETURNING`).WithArgs("ip:127.0.0.1", now, now, sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"id", "throttle_key", "failures", "last_failure", "previous_failure"}).AddRow(1, "ip:127.0.0.1", 4, now, previous))
+			failures, err := store.AddFailure("ip:127.0.0.1", now, now.Add(-time.Hour))
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(failures.Count).To(Equal(4))
+			Expect(failures.Previous).To(Equal(previous))
+		})
+		It("should take back a failed login in the database", func() {
+			var c dataaccess.Connection
+			c, mock = dataaccess.NewMockConnection()
+			store := logic.NewDatabaseLoginAttemptStore(da.NewLoginAttemptRepository(c))
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "login_attempts" SET "failures"=failures - 1 WHERE throttle_key = \$1 AND failures > 0`).WithArgs("ip:127.0.0.1").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := store.RemoveFailure("ip:127.0.0.1")
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+	Context("Admin", func() {
+		playerColumns := []string{"id", "name", "display_name", "game_id", "status", "role"}
+		It("should promote a player to another admin", func() {
+			changeRoleTo := to.ChangeRoleTo{Name: "Moritz", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("moritz", 1).WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(2, "Moritz", "Moritz", 1, "Ready", "Player"))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Moritz", "Moritz", "moritz", "", 1, "Ready", "Admin", nil, false, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := gamemanagement.PromotePlayer(changeRoleTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should demote an admin if another admin is left", func() {
+			changeRoleTo := to.ChangeRoleTo{Name: "Moritz", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("moritz", 1).WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(2, "Moritz", "Moritz", 1, "Ready", "Admin"))
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT .* FOR UPDATE`).WithArgs(1, "Admin", "Organizer").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(1, "Max", "Max", 1, "Ready", "Admin").AddRow(2, "Moritz", "Moritz", 1, "Ready", "Admin"))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Moritz", "Moritz", "moritz", "", 1, "Ready", "Player", nil, false, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := gamemanagement.DemotePlayer(changeRoleTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should not demote the last admin", func() {
+			changeRoleTo := to.ChangeRoleTo{Name: "Max", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(1, "Max", "Max", 1, "Ready", "Admin"))
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT .* FOR UPDATE`).WithArgs(1, "Admin", "Organizer").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(1, "Max", "Max", 1, "Ready", "Admin"))
+			mock.ExpectRollback()
+			err := gamemanagement.DemotePlayer(changeRoleTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrLastAdmin))
+		})
+		It("should not remove the last admin from the game", func() {
+			addRemovePlayerTo := to.AddRemovePlayerTo{Name: "Max", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(1, "Max", "Max", 1, "Ready", "Admin"))
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT .* FOR UPDATE`).WithArgs(1, "Admin", "Organizer").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(1, "Max", "Max", 1, "Ready", "Admin"))
+			mock.ExpectRollback()
+			err := gamemanagement.RemovePlayerFromGame(addRemovePlayerTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrLastAdmin))
+		})
+		It("should transfer the admin role to another player", func() {
+			transferAdminTo := to.TransferAdminTo{From: "Max", To: "Moritz", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(1, "Max", "Max", 1, "Ready", "Admin"))
+			mock.ExpectQuery("SELECT").WithArgs("moritz", 1).WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(2, "Moritz", "Moritz", 1, "Ready", "Player"))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Moritz", "Moritz", "moritz", "", 1, "Ready", "Admin", nil, false, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", "Max", "max", "", 1, "Ready", "Player", nil, false, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := gamemanagement.TransferAdmin(transferAdminTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should not transfer the admin role from a player who is no admin", func() {
+			transferAdminTo := to.TransferAdminTo{From: "Moritz", To: "Susi", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("moritz", 1).WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(2, "Moritz", "Moritz", 1, "Ready", "Player"))
+			err := gamemanagement.TransferAdmin(transferAdminTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrPlayerNotAdmin))
+		})
+		It("should not let an organizer take part after the draw", func() {
+			changeRoleTo := to.ChangeRoleTo{Name: "Chefin", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "Drawn"))
+			mock.ExpectQuery("SELECT").WithArgs("chefin", 1).WillReturnRows(sqlmock.NewRows(playerColumns).AddRow(4, "Chefin", "Chefin", 1, "Ready", "Organizer"))
+			err := gamemanagement.DemotePlayer(changeRoleTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrGameAlreadyDrawn))
+		})
+		It("should count an organizer as admin", func() {
+			Expect(da.CanManage(da.RoleOrganizer.String())).To(BeTrue())
+			Expect(da.TakesPart(da.RoleOrganizer.String())).To(BeFalse())
+			Expect(da.CanManage(da.RolePlayer.String())).To(BeFalse())
+			Expect(da.TakesPart(da.RoleAdmin.String())).To(BeTrue())
+		})
+		It("should fail to promote a player who does not exist", func() {
+			changeRoleTo := to.ChangeRoleTo{Name: "Susi", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("susi", 1).WillReturnRows(sqlmock.NewRows(playerColumns))
+			err := gamemanagement.PromotePlayer(changeRoleTo)
+			Expect(err).To(MatchError(errors.ErrPlayerNotFound))
+		})
+		It("should pass on a failing database instead of a missing player", func() {
+			changeRoleTo := to.ChangeRoleTo{Name: "Moritz", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("moritz", 1).WillReturnError(fmt.Errorf("connection lost"))
+			err := gamemanagement.DemotePlayer(changeRoleTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError("connection lost"))
+		})
 	})
 	Context("PlayerException", func() {
 		It("should be able to be added to a game", func() {
 			addExceptionTo := to.AddExceptionTo{NameA: "Max", NameB: "Erika", GameCode: "ABC"}
-			expectDefaultQuery(mock)
-			expectDefaultQuery(mock)
-			expectDefaultQuery(mock)
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1))
+			mock.ExpectQuery("SELECT").WithArgs("erika", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(2, "Erika", 1))
 			expectDefaultQueryWithNoResult(mock)
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Erika").AddRow(3, "Susi"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
 			mock.ExpectBegin()
-			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", 0, "", "", nil, 1).WillReturnRows(mock.NewRows([]string{"id"}).AddRow((1)))
-			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "", "", 0, "", "", nil, 1).WillReturnRows(mock.NewRows([]string{"id"}).AddRow((1)))
-			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 1, 1).WillReturnRows(mock.NewRows([]string{"id"}).AddRow((1)))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", "Max", "max", "", 1, "", "", nil, false, 1).WillReturnRows(mock.NewRows([]string{"id"}).AddRow((1)))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Erika", "Erika", "erika", "", 1, "", "", nil, false, 2).WillReturnRows(mock.NewRows([]string{"id"}).AddRow((2)))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, 1).WillReturnRows(mock.NewRows([]string{"id"}).AddRow((1)))
 			mock.ExpectCommit()
-			err := gamemanagement.AddException(addExceptionTo)
+			feasibilityResponseTo, err := gamemanagement.AddException(addExceptionTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(feasibilityResponseTo.Feasible).To(BeTrue())
+		})
+		It("should be rejected if the game could not be drawn anymore", func() {
+			addExceptionTo := to.AddExceptionTo{NameA: "Max", NameB: "Erika", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1))
+			mock.ExpectQuery("SELECT").WithArgs("erika", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(2, "Erika", 1))
+			expectDefaultQueryWithNoResult(mock)
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Erika"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+			feasibilityResponseTo, err := gamemanagement.AddException(addExceptionTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrDrawInfeasible))
+			Expect(feasibilityResponseTo.Feasible).To(BeFalse())
+			Expect(feasibilityResponseTo.Players).To(ConsistOf("Max"))
+			Expect(feasibilityResponseTo.Exceptions).To(ConsistOf(to.ExceptionResponseTo{NameA: "Max", NameB: "Erika"}))
+		})
+		It("should explain which players and exceptions block the draw", func() {
+			code := "ABC"
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz").AddRow(3, "Susi").AddRow(4, "Strolch"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 1, 3).AddRow(2, 1, 4).AddRow(3, 2, 3).AddRow(4, 2, 4).AddRow(5, 3, 4))
+			mock.ExpectQuery("SELECT").WithArgs(1, 2, 3).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz").AddRow(3, "Susi"))
+			mock.ExpectQuery("SELECT").WithArgs(3, 4).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Susi").AddRow(4, "Strolch"))
+			feasibilityResponseTo, err := gamemanagement.CheckFeasibility(code)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
 			Expect(err).ToNot(HaveOccurred())
+			Expect(feasibilityResponseTo.Feasible).To(BeFalse())
+			Expect(feasibilityResponseTo.Players).To(ConsistOf("Max", "Moritz", "Susi"))
+			Expect(feasibilityResponseTo.Exceptions).To(ConsistOf(
+				to.ExceptionResponseTo{NameA: "Max", NameB: "Susi"},
+				to.ExceptionResponseTo{NameA: "Max", NameB: "Strolch"},
+				to.ExceptionResponseTo{NameA: "Moritz", NameB: "Susi"},
+				to.ExceptionResponseTo{NameA: "Moritz", NameB: "Strolch"},
+				to.ExceptionResponseTo{NameA: "Susi", NameB: "Strolch"},
+			))
 		})
 		It("should fail to be added with empty information", func() {
 			addExceptionTo := to.AddExceptionTo{}
-			err := gamemanagement.AddException(addExceptionTo)
+			_, err := gamemanagement.AddException(addExceptionTo)
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(BeAssignableToTypeOf(validator.ValidationErrors{}))
 			valErr := err.(validator.ValidationErrors)
@@ -604,7 +1387,7 @@ Key: 'RegisterLoginPlayerPasswordTo.Password' Error:Field validation for 'Passwo
 		It("should fail to be added because game does not exist", func() {
 			addExceptionTo := to.AddExceptionTo{NameA: "Max", NameB: "Erika", GameCode: "ABC"}
 			expectDefaultQueryWithNoResult(mock)
-			err := gamemanagement.AddException(addExceptionTo)
+			_, err := gamemanagement.AddException(addExceptionTo)
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(gorm.ErrRecordNotFound))
 		})
@@ -612,7 +1395,7 @@ Key: 'RegisterLoginPlayerPasswordTo.Password' Error:Field validation for 'Passwo
 			addExceptionTo := to.AddExceptionTo{NameA: "Max", NameB: "Erika", GameCode: "ABC"}
 			expectDefaultQuery(mock)
 			expectDefaultQueryWithNoResult(mock)
-			err := gamemanagement.AddException(addExceptionTo)
+			_, err := gamemanagement.AddException(addExceptionTo)
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(gorm.ErrRecordNotFound))
 		})
@@ -621,7 +1404,7 @@ Key: 'RegisterLoginPlayerPasswordTo.Password' Error:Field validation for 'Passwo
 			expectDefaultQuery(mock)
 			expectDefaultQuery(mock)
 			expectDefaultQueryWithNoResult(mock)
-			err := gamemanagement.AddException(addExceptionTo)
+			_, err := gamemanagement.AddException(addExceptionTo)
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(gorm.ErrRecordNotFound))
 		})
@@ -631,18 +1414,18 @@ Key: 'RegisterLoginPlayerPasswordTo.Password' Error:Field validation for 'Passwo
 			expectDefaultQuery(mock)
 			expectDefaultQuery(mock)
 			expectDefaultQuery(mock)
-			err := gamemanagement.AddException(addExceptionTo)
+			_, err := gamemanagement.AddException(addExceptionTo)
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(errors.ErrPlayerExceptionAlreadyExists))
 		})
 		It("should return all exceptions by game code", func() {
 			code := "ABC"
-			exceptionA := to.ExceptionResponseTo{NameA: "Max", NameB: "Moritz"}
-			exceptionB := to.ExceptionResponseTo{NameA: "Susi", NameB: "Strolch"}
+			exceptionA := to.ExceptionResponseTo{NameA: "Max", NameB: "Moritz", DisplayNameA: "Maximilian", DisplayNameB: "Moritz"}
+			exceptionB := to.ExceptionResponseTo{NameA: "Susi", NameB: "Strolch", DisplayNameA: "Susanne", DisplayNameB: "Strolch"}
 			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 3, 4).AddRow(2, 5, 6))
-			mock.ExpectQuery("SELECT").WithArgs(3, 5).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Max").AddRow(5, "Susi"))
-			mock.ExpectQuery("SELECT").WithArgs(4, 6).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(4, "Moritz").AddRow(6, "Strolch"))
+			mock.ExpectQuery("SELECT").WithArgs(3, 5).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(3, "Max", "Maximilian").AddRow(5, "Susi", "Susanne"))
+			mock.ExpectQuery("SELECT").WithArgs(4, 6).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(4, "Moritz", "Moritz").AddRow(6, "Strolch", "Strolch"))
 			exceptions, err := gamemanagement.GetExceptionsByCode(code)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
@@ -673,5 +1456,233 @@ Key: 'RegisterLoginPlayerPasswordTo.Password' Error:Field validation for 'Passwo
 			Expect(err).To(MatchError(gorm.ErrInvalidData))
 			Expect(exceptions).To(BeEmpty())
 		})
+		It("should be able to be removed from a game", func() {
+			removeExceptionTo := to.RemoveExceptionTo{NameA: "Max", NameB: "Erika", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1))
+			mock.ExpectQuery("SELECT").WithArgs("erika", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(2, "Erika", 1))
+			mock.ExpectQuery("SELECT").WithArgs(1, 2, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "game_id"}).AddRow(5, 1, 2, 1))
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "player_exceptions"`).WithArgs(sqlmock.AnyArg(), 5).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := gamemanagement.RemoveException(removeExceptionTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should fail to remove an exception which does not exist", func() {
+			removeExceptionTo := to.RemoveExceptionTo{NameA: "Max", NameB: "Erika", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1))
+			mock.ExpectQuery("SELECT").WithArgs("erika", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(2, "Erika", 1))
+			expectDefaultQueryWithNoResult(mock)
+			err := gamemanagement.RemoveException(removeExceptionTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(gorm.ErrRecordNotFound))
+		})
+		It("should be able to be replaced by an exclusion matrix", func() {
+			replaceExceptionsTo := to.ReplaceExceptionsTo{GameCode: "ABC", Players: []string{"Max", "Erika"}, Excluded: [][]bool{{false, true}, {false, false}}}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1).AddRow(2, "Erika", 1).AddRow(3, "Susi", 1))
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "player_exceptions"`).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectQuery(`INSERT INTO "players"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", "Max", "max", "", 1, "", "", nil, false, 1).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery(`INSERT INTO "players"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Erika", "Erika", "erika", "", 1, "", "", nil, false, 2).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(2))
+			mock.ExpectQuery(`INSERT INTO "player_exceptions"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, 1).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(6))
+			mock.ExpectCommit()
+			feasibilityResponseTo, err := gamemanagement.ReplaceExceptions(replaceExceptionsTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(feasibilityResponseTo.Feasible).To(BeTrue())
+		})
+		It("should fail to be replaced by a matrix with players of another game", func() {
+			replaceExceptionsTo := to.ReplaceExceptionsTo{GameCode: "ABC", Players: []string{"Max", "Bello"}, Excluded: [][]bool{{false, true}, {false, false}}}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1).AddRow(2, "Erika", 1))
+			_, err := gamemanagement.ReplaceExceptions(replaceExceptionsTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrPlayerNotFound))
+		})
+		It("should fail to be replaced by a matrix which does not match the players", func() {
+			replaceExceptionsTo := to.ReplaceExceptionsTo{GameCode: "ABC", Players: []string{"Max", "Erika"}, Excluded: [][]bool{{false, true}}}
+			_, err := gamemanagement.ReplaceExceptions(replaceExceptionsTo)
+			Expect(err).To(MatchError(errors.ErrInvalidExceptionMatrix))
+		})
+		It("should fail to be replaced by a matrix listing a player twice", func() {
+			replaceExceptionsTo := to.ReplaceExceptionsTo{GameCode: "ABC", Players: []string{"Max", "Max"}, Excluded: [][]bool{{false, false}, {false, false}}}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1).AddRow(2, "Erika", 1))
+			_, err := gamemanagement.ReplaceExceptions(replaceExceptionsTo)
+			Expect(err).To(MatchError(errors.ErrInvalidExceptionMatrix))
+		})
+		It("should fail to be replaced by a matrix listing a player twice with a different spelling", func() {
+			replaceExceptionsTo := to.ReplaceExceptionsTo{GameCode: "ABC", Players: []string{"Max", " mAX "}, Excluded: [][]bool{{false, false}, {false, false}}}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1).AddRow(2, "Erika", 1))
+			_, err := gamemanagement.ReplaceExceptions(replaceExceptionsTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrInvalidExceptionMatrix))
+		})
+		It("should fail to be replaced by a matrix which makes the draw impossible", func() {
+			replaceExceptionsTo := to.ReplaceExceptionsTo{GameCode: "ABC", Players: []string{"Max", "Erika"}, Excluded: [][]bool{{false, true}, {false, false}}}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1).AddRow(2, "Erika", 1))
+			feasibilityResponseTo, err := gamemanagement.ReplaceExceptions(replaceExceptionsTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrDrawInfeasible))
+			Expect(feasibilityResponseTo.Feasible).To(BeFalse())
+		})
+	})
+	Describe("PlayerPreference", func() {
+		It("should be able to be added to a game", func() {
+			addPreferenceTo := to.AddPreferenceTo{NameA: "Max", NameB: "Moritz", Weight: 3, GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1))
+			mock.ExpectQuery("SELECT").WithArgs("moritz", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(2, "Moritz", 1))
+			mock.ExpectQuery("SELECT").WithArgs(1, 2, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectBegin()
+			mock.ExpectQuery("INSERT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, 3, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectCommit()
+			err := gamemanagement.AddPreference(addPreferenceTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should fail to be added without weight", func() {
+			addPreferenceTo := to.AddPreferenceTo{NameA: "Max", NameB: "Moritz", GameCode: "ABC"}
+			err := gamemanagement.AddPreference(addPreferenceTo)
+			Expect(err).To(BeAssignableToTypeOf(validator.ValidationErrors{}))
+		})
+		It("should fail to be added if it already exists", func() {
+			addPreferenceTo := to.AddPreferenceTo{NameA: "Max", NameB: "Moritz", Weight: 3, GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1))
+			mock.ExpectQuery("SELECT").WithArgs("moritz", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(2, "Moritz", 1))
+			mock.ExpectQuery("SELECT").WithArgs(1, 2, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			err := gamemanagement.AddPreference(addPreferenceTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrPlayerPreferenceAlreadyExists))
+		})
+		It("should be able to be removed from a game", func() {
+			removePreferenceTo := to.RemovePreferenceTo{NameA: "Max", NameB: "Moritz", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1))
+			mock.ExpectQuery("SELECT").WithArgs("moritz", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(2, "Moritz", 1))
+			mock.ExpectQuery("SELECT").WithArgs(1, 2, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 5).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := gamemanagement.RemovePreference(removePreferenceTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should return all preferences by game code", func() {
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}).AddRow(1, 1, 2, 3))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(1, "Max", "Maximilian"))
+			mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name"}).AddRow(2, "Moritz", "Moritz"))
+			preferences, err := gamemanagement.GetPreferencesByCode("ABC")
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(preferences).To(ConsistOf(to.PreferenceResponseTo{NameA: "Max", NameB: "Moritz", Weight: 3, DisplayNameA: "Maximilian", DisplayNameB: "Moritz"}))
+		})
+		It("should be respected by the draw", func() {
+			code := "ABC"
+			drawGameTo := to.DrawGameTo{GameCode: code}
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}).AddRow(1, 1, 2, 5).AddRow(2, 2, 1, 1))
+			mock.ExpectQuery("SELECT").WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz"))
+			mock.ExpectQuery("SELECT").WithArgs(2, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz"))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(false, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 1, 3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 3, 2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+			mock.ExpectQuery(`INSERT INTO "draw_audits"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+			drawGameResponseTo, err := gamemanagement.DrawGame(drawGameTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawGameResponseTo.Ok).To(BeTrue())
+			Expect(drawGameResponseTo.Score).To(Equal(1))
+		})
+	})
+	Describe("Household", func() {
+		It("should be able to be added to a game", func() {
+			addHouseholdTo := to.AddRemoveHouseholdTo{Name: "Müller", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("Müller", 1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectBegin()
+			mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Müller", 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectCommit()
+			err := gamemanagement.AddHousehold(addHouseholdTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should fail to be added if it already exists", func() {
+			addHouseholdTo := to.AddRemoveHouseholdTo{Name: "Müller", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("Müller", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Müller"))
+			err := gamemanagement.AddHousehold(addHouseholdTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrHouseholdAlreadyExists))
+		})
+		It("should release its members when removed", func() {
+			removeHouseholdTo := to.AddRemoveHouseholdTo{Name: "Müller", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("Müller", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "Müller"))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(nil, sqlmock.AnyArg(), 7).WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 7).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			err := gamemanagement.RemoveHousehold(removeHouseholdTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should assign a player to a household", func() {
+			assignHouseholdTo := to.AssignHouseholdTo{PlayerName: "Max", HouseholdName: "Müller", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready"))
+			mock.ExpectQuery("SELECT").WithArgs("Müller", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "Müller"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "household_id"}).AddRow(1, "Max", nil).AddRow(2, "Moritz", 7).AddRow(3, "Susi", nil).AddRow(4, "Strolch", nil))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Max", "Max", "max", "", 1, "Ready", "", 7, false, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			feasibilityResponseTo, err := gamemanagement.AssignPlayerToHousehold(assignHouseholdTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(feasibilityResponseTo.Feasible).To(BeTrue())
+		})
+		It("should reject an assignment if the game could not be drawn anymore", func() {
+			assignHouseholdTo := to.AssignHouseholdTo{PlayerName: "Max", HouseholdName: "Müller", GameCode: "ABC"}
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(1, "Max", 1))
+			mock.ExpectQuery("SELECT").WithArgs("Müller", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "Müller"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "household_id"}).AddRow(1, "Max", nil).AddRow(2, "Moritz", 7))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "Müller"))
+			feasibilityResponseTo, err := gamemanagement.AssignPlayerToHousehold(assignHouseholdTo)
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(errors.ErrDrawInfeasible))
+			Expect(feasibilityResponseTo.Feasible).To(BeFalse())
+			Expect(feasibilityResponseTo.Households).To(ConsistOf("Müller"))
+		})
+		It("should return all households with their members", func() {
+			mock.ExpectQuery("SELECT").WithArgs("ABC").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "Müller").AddRow(8, "Schmidt"))
+			mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name", "household_id"}).AddRow(1, "Max", "Maximilian", 7).AddRow(2, "Moritz", "Moritz", 7).AddRow(3, "Susi", "Susi", nil))
+			households, err := gamemanagement.GetHouseholdsByCode("ABC")
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(households).To(ConsistOf(
+				to.HouseholdResponseTo{Name: "Müller", Players: []string{"Max", "Moritz"}, DisplayNames: []string{"Maximilian", "Moritz"}},
+				to.HouseholdResponseTo{Name: "Schmidt", Players: []string{}, DisplayNames: []string{}},
+			))
+		})
 	})
 })
@@ -0,0 +1,113 @@
+package logic
+
+import (
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/dataaccess"
+	gerr "github.com/yoktobit/secretsanta/internal/gamemanagement/logic/errors"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// pairing is a giver→receiver pair of a previous game. Players are matched by name,
+// because every game has its own players.
+type pairing struct {
+	giver    string
+	receiver string
+}
+
+// loadPairingHistory returns the pairings of the previous games of a game, the game of last year first.
+// At most game.AvoidRepeatYears games are followed along the chain of previous games.
+func (gamemanagement *gamemanagement) loadPairingHistory(game *dataaccess.Game) ([][]pairing, error) {
+
+	history := make([][]pairing, 0)
+	previousGameID := game.PreviousGameID
+	for years := 0; years < game.AvoidRepeatYears && previousGameID != nil; years++ {
+		previousGame, err := gamemanagement.gameRepository.FindGameByID(*previousGameID)
+		if err != nil {
+			return history, err
+		}
+		players, err := gamemanagement.playerRepository.FindPlayersByGameID(previousGame.ID)
+		if err != nil {
+			return history, err
+		}
+		assignments, err := gamemanagement.assignmentRepository.FindAssignmentsByGameID(previousGame.ID)
+		if err != nil {
+			return history, err
+		}
+		names := make(map[uint]string)
+		for _, player := range players {
+			names[player.ID] = dataaccess.NormalizeName(player.Name)
+		}
+		pairings := make([]pairing, 0)
+		for _, assignment := range assignments {
+			giver, giverFound := names[assignment.GiverID]
+			receiver, receiverFound := names[assignment.ReceiverID]
+			if giverFound && receiverFound {
+				pairings = append(pairings, pairing{giver: giver, receiver: receiver})
+			}
+		}
+		history = append(history, pairings)
+		previousGameID = previousGame.PreviousGameID
+	}
+	return history, nil
+}
+
+// findPreviousGame looks up the game of the year before to link it to a game. Only an admin of the previous game
+// may link it, which is proven by the name and the password of that admin, so that no other group's pairings leak.
+func (gamemanagement *gamemanagement) findPreviousGame(game *dataaccess.Game, previousGameCode string, name string, password string) (dataaccess.Game, error) {
+
+	if previousGameCode == game.Code {
+		return dataaccess.Game{}, gerr.ErrInvalidPreviousGame
+	}
+	previousGame, err := gamemanagement.gameRepository.FindGameByCode(previousGameCode)
+	if err != nil {
+		return previousGame, err
+	}
+	admin, err := gamemanagement.playerRepository.FindPlayerByNameAndGameID(name, previousGame.ID)
+	if err == gorm.ErrRecordNotFound {
+		return dataaccess.Game{}, gerr.ErrPreviousGameForbidden
+	}
+	if err != nil {
+		return dataaccess.Game{}, err
+	}
+	if !dataaccess.CanManage(admin.Role) || admin.Password == "" || bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(password)) != nil {
+		return dataaccess.Game{}, gerr.ErrPreviousGameForbidden
+	}
+	return previousGame, nil
+}
+
+// pairingIndices translates the pairings of previous years to [giver, receiver] indices of the current players.
+// Pairings with players who do not take part this year are left out.
+func pairingIndices(players []*dataaccess.Player, history [][]pairing) [][][2]int {
+
+	index := make(map[string]int)
+	for i, player := range players {
+		index[dataaccess.NormalizeName(player.Name)] = i
+	}
+	indices := make([][][2]int, len(history))
+	for year, pairings := range history {
+		indices[year] = make([][2]int, 0, len(pairings))
+		for _, pair := range pairings {
+			giver, giverFound := index[pair.giver]
+			receiver, receiverFound := index[pair.receiver]
+			if giverFound && receiverFound {
+				indices[year] = append(indices[year], [2]int{giver, receiver})
+			}
+		}
+	}
+	return indices
+}
+
+// excludePairings forbids all pairings of the given years in a copy of allowed
+func excludePairings(allowed [][]bool, history [][][2]int) [][]bool {
+
+	restricted := make([][]bool, len(allowed))
+	for giver := range allowed {
+		restricted[giver] = append([]bool(nil), allowed[giver]...)
+	}
+	for _, pairings := range history {
+		for _, pair := range pairings {
+			restricted[pair[0]][pair[1]] = false
+		}
+	}
+	return restricted
+}
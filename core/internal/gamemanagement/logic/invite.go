@@ -0,0 +1,44 @@
+package logic
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/dataaccess"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+	gda "github.com/yoktobit/secretsanta/internal/general/dataaccess"
+)
+
+// inviteValidity is how long a player can register with an invite
+const inviteValidity = 14 * 24 * time.Hour
+
+// newInviteToken generates a token which can not be guessed
+func newInviteToken() (string, error) {
+
+	token := make([]byte, 32)
+	_, err := crand.Read(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// hashInviteToken hashes a token, only the hash is saved so that the tokens can not be read from the database
+func hashInviteToken(token string) string {
+
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// createInvite replaces the invites of a player by a new one and returns the token for the invite link
+func (gamemanagement *gamemanagement) createInvite(c gda.Connection, player *dataaccess.Player, token string) to.InviteTo {
+
+	expiresAt := time.Now().Add(inviteValidity)
+	gamemanagement.inviteRepository.DeleteInvitesByPlayerID(c, player.ID)
+	invite := dataaccess.Invite{GameID: player.GameID, PlayerID: player.ID, TokenHash: hashInviteToken(token), ExpiresAt: expiresAt}
+	gamemanagement.inviteRepository.CreateInvite(c, &invite)
+	return to.InviteTo{Name: player.Name, Token: token, ExpiresAt: expiresAt}
+}
@@ -5,13 +5,29 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	da "github.com/yoktobit/secretsanta/internal/gamemanagement/dataaccess"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic"
 	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+	gl "github.com/yoktobit/secretsanta/internal/general/logic"
 )
 
 func NewCreateGameTo() to.CreateGameTo {
 	return to.CreateGameTo{Title: "ABC", Description: "DEF", AdminUser: "Martin", AdminPassword: "Test12345"}
 }
 
+// newTestGamemanagement creates a gamemanagement on the given connection with the mocked randomizer,
+// a nil login attempt store or nil draw strategies are replaced by the defaults
+func newTestGamemanagement(c dataaccess.Connection, loginAttemptStore logic.LoginAttemptStore, drawStrategies *logic.DrawStrategies) logic.Gamemanagement {
+	if loginAttemptStore == nil {
+		loginAttemptStore = logic.NewMemoryLoginAttemptStore()
+	}
+	if drawStrategies == nil {
+		drawStrategies = logic.NewDefaultDrawStrategies()
+	}
+	return logic.NewGamemanagement(c, da.NewGameRepository(c), da.NewPlayerRepository(c), da.NewPlayerExceptionRepository(c), da.NewHouseholdRepository(c), da.NewAssignmentRepository(c), da.NewPlayerPreferenceRepository(c), da.NewDrawAuditRepository(c), da.NewDrawRoundRepository(c), da.NewInviteRepository(c), da.NewLoginAuditRepository(c), loginAttemptStore, drawStrategies, gl.NewMockRandomizer())
+}
+
 func TestLogic(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Logic Suite")
@@ -0,0 +1,249 @@
+package logic
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/dataaccess"
+	gda "github.com/yoktobit/secretsanta/internal/general/dataaccess"
+)
+
+// loginBaseDelay is the wait after the first failed login which is not free, it doubles with every further failure
+const loginBaseDelay = time.Second
+
+// loginMaxDelay caps the exponential backoff
+const loginMaxDelay = 5 * time.Minute
+
+// loginLockoutDuration is how long a key is locked once it reaches its lockout threshold
+const loginLockoutDuration = time.Hour
+
+// loginFailureWindow is after how long without another failure the failed logins of a key are forgotten
+const loginFailureWindow = 24 * time.Hour
+
+// loginAttemptPruneInterval is how often the memory store forgets the keys without failures in the loginFailureWindow
+const loginAttemptPruneInterval = 10 * time.Minute
+
+// loginLimit says how many failed logins a key may have
+type loginLimit struct {
+	// free is how many failed logins are allowed without waiting
+	free int
+	// lockout is how many failed logins lock the key for loginLockoutDuration, 0 if the key is never locked
+	lockout int
+	// keepOnSuccess keeps the failures of the key after a successful login, only the successful attempt is taken back
+	keepOnSuccess bool
+}
+
+// playerLoginLimit throttles the guessing of a single password from many clients. It only slows the guessing down
+// and never locks the player, otherwise anyone could lock a player out with a few wrong passwords. While someone keeps
+// guessing, the player has to wait up to loginMaxDelay as well.
+var playerLoginLimit = loginLimit{free: 3}
+
+// playerClientLoginLimit throttles the guessing of a single password from one client, which is locked after a while
+var playerClientLoginLimit = loginLimit{free: 3, lockout: 10}
+
+// ipLoginLimit throttles a client trying many players, it is more generous because households share an IP
+var ipLoginLimit = loginLimit{free: 10, lockout: 50, keepOnSuccess: true}
+
+// LoginFailures are the failed logins of a throttle key
+type LoginFailures struct {
+	Count int
+	Last  time.Time
+	// Previous is the failure before the last one
+	Previous time.Time
+}
+
+// A LoginAttemptStore counts the failed logins per throttle key
+type LoginAttemptStore interface {
+	// AddFailure counts a failed login of a key at the given time and returns the failures including it,
+	// failures before since are forgotten. Concurrent calls for the same key get different counts.
+	AddFailure(key string, at time.Time, since time.Time) (LoginFailures, error)
+	// RemoveFailure takes back a failed login of a key
+	RemoveFailure(key string) error
+	// Reset forgets the failed logins of a key
+	Reset(key string) error
+}
+
+type memoryLoginAttemptStore struct {
+	mutex    sync.Mutex
+	failures map[string]LoginFailures
+}
+
+type databaseLoginAttemptStore struct {
+	loginAttemptRepository dataaccess.LoginAttemptRepository
+}
+
+// NewMemoryLoginAttemptStore creates a store which keeps the failed logins in memory, it is not shared between instances
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+
+	store := &memoryLoginAttemptStore{failures: map[string]LoginFailures{}}
+	go store.pruneEvery(loginAttemptPruneInterval)
+	return store
+}
+
+// NewDatabaseLoginAttemptStore creates a store which keeps the failed logins in the database, so that several instances share them
+func NewDatabaseLoginAttemptStore(loginAttemptRepository dataaccess.LoginAttemptRepository) LoginAttemptStore {
+
+	return &databaseLoginAttemptStore{loginAttemptRepository: loginAttemptRepository}
+}
+
+// NewLoginAttemptStoreWithEnvironment creates the store configured by the environment parameter LOGIN_ATTEMPT_STORE,
+// which is "memory" (default) or "postgres"
+func NewLoginAttemptStoreWithEnvironment(loginAttemptRepository dataaccess.LoginAttemptRepository) LoginAttemptStore {
+
+	switch kind := os.Getenv("LOGIN_ATTEMPT_STORE"); kind {
+	case "", "memory":
+		return NewMemoryLoginAttemptStore()
+	case "postgres":
+		return NewDatabaseLoginAttemptStore(loginAttemptRepository)
+	default:
+		panic("Unknown login attempt store " + kind)
+	}
+}
+
+func (store *memoryLoginAttemptStore) AddFailure(key string, at time.Time, since time.Time) (LoginFailures, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	failures := store.failures[key]
+	if failures.Last.Before(since) {
+		failures = LoginFailures{}
+	}
+	failures.Count++
+	failures.Previous = failures.Last
+	failures.Last = at
+	store.failures[key] = failures
+	return failures, nil
+}
+
+func (store *memoryLoginAttemptStore) RemoveFailure(key string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if failures, ok := store.failures[key]; ok && failures.Count > 0 {
+		failures.Count--
+		store.failures[key] = failures
+	}
+	return nil
+}
+
+func (store *memoryLoginAttemptStore) Reset(key string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	delete(store.failures, key)
+	return nil
+}
+
+// pruneEvery forgets the keys without failures in the loginFailureWindow from time to time,
+// so that the store does not keep every client and player which ever failed to log in
+func (store *memoryLoginAttemptStore) pruneEvery(interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	for now := range ticker.C {
+		store.prune(now.Add(-loginFailureWindow))
+	}
+}
+
+func (store *memoryLoginAttemptStore) prune(since time.Time) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	for key, failures := range store.failures {
+		if failures.Last.Before(since) {
+			delete(store.failures, key)
+		}
+	}
+}
+
+func (store *databaseLoginAttemptStore) AddFailure(key string, at time.Time, since time.Time) (LoginFailures, error) {
+	loginAttempt, err := store.loginAttemptRepository.AddLoginFailure(key, at, since)
+	return LoginFailures{Count: loginAttempt.Failures, Last: loginAttempt.LastFailure, Previous: loginAttempt.PreviousFailure}, err
+}
+
+func (store *databaseLoginAttemptStore) RemoveFailure(key string) error {
+	return store.loginAttemptRepository.RemoveLoginFailure(key)
+}
+
+func (store *databaseLoginAttemptStore) Reset(key string) error {
+	return store.loginAttemptRepository.DeleteLoginAttemptByKey(key)
+}
+
+// lockedUntil returns until when a key with the given failures has to wait, the zero time if it does not
+func (limit loginLimit) lockedUntil(failures LoginFailures) time.Time {
+
+	if limit.lockout > 0 && failures.Count >= limit.lockout {
+		return failures.Last.Add(loginLockoutDuration)
+	}
+	if failures.Count <= limit.free {
+		return time.Time{}
+	}
+	delay := loginMaxDelay
+	if doublings := failures.Count - limit.free - 1; doublings < 16 {
+		delay = loginBaseDelay << uint(doublings)
+	}
+	if delay > loginMaxDelay {
+		delay = loginMaxDelay
+	}
+	return failures.Last.Add(delay)
+}
+
+// loginThrottleKeys returns the throttle keys of a login with their limits, the player keys only if game code and name are given
+func loginThrottleKeys(clientIP string, gameCode string, name string) map[string]loginLimit {
+
+	keys := map[string]loginLimit{"ip:" + clientIP: ipLoginLimit}
+	if gameCode != "" && name != "" {
+		keys[playerThrottleKey(gameCode, name)] = playerLoginLimit
+		keys[playerThrottleKey(gameCode, name)+":"+clientIP] = playerClientLoginLimit
+	}
+	return keys
+}
+
+// playerThrottleKey is the throttle key of a player, the IP is left out so that a password can not be guessed from many clients
+func playerThrottleKey(gameCode string, name string) string {
+
+	return "player:" + gameCode + ":" + dataaccess.NormalizeName(name)
+}
+
+// countLoginAttempt counts a login as failed for all keys before it is checked, so that concurrent logins can not
+// pass the throttling together. It returns how long the login has to wait because of the failures before it.
+func (gamemanagement *gamemanagement) countLoginAttempt(keys map[string]loginLimit, now time.Time) (time.Duration, error) {
+
+	var retryAfter time.Duration
+	for key, limit := range keys {
+		failures, err := gamemanagement.loginAttemptStore.AddFailure(key, now, now.Add(-loginFailureWindow))
+		if err != nil {
+			return 0, err
+		}
+		before := LoginFailures{Count: failures.Count - 1, Last: failures.Previous}
+		if wait := limit.lockedUntil(before).Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	return retryAfter, nil
+}
+
+// acceptLogin forgets the failed logins of a successful login, the keys which keep their failures only forget this attempt
+func (gamemanagement *gamemanagement) acceptLogin(keys map[string]loginLimit) {
+
+	for key, limit := range keys {
+		var err error
+		if limit.keepOnSuccess {
+			err = gamemanagement.loginAttemptStore.RemoveFailure(key)
+		} else {
+			err = gamemanagement.loginAttemptStore.Reset(key)
+		}
+		if err != nil {
+			log.Errorln("Failed to reset the login attempts", err)
+		}
+	}
+}
+
+// auditLogin records a rejected login in the audit trail
+func (gamemanagement *gamemanagement) auditLogin(gameCode string, name string, clientIP string, reason string) {
+
+	log.Warnf("Login of %q in game %s from %s rejected: %s", name, gameCode, clientIP, reason)
+	err := gamemanagement.Connection().NewTransaction(func(c gda.Connection) error {
+		return gamemanagement.loginAuditRepository.CreateLoginAudit(c, &dataaccess.LoginAudit{GameCode: gameCode, Name: name, ClientIP: clientIP, Reason: reason})
+	})
+	if err != nil {
+		log.Errorln("Failed to record the rejected login", err)
+	}
+}
@@ -0,0 +1,104 @@
+package logic
+
+import (
+	"sort"
+
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+	glogic "github.com/yoktobit/secretsanta/internal/general/logic"
+)
+
+// previewSamples is how many random assignments are used to estimate what can not be counted exactly
+const previewSamples = 2000
+
+// maxConstrainedPlayers is how many of the most constrained players a preview lists
+const maxConstrainedPlayers = 3
+
+// countAssignments returns the number of valid assignments. Above maxExactDrawSize players it is estimated
+// by sequential importance sampling: every giver picks one of its free receivers at random, and the product
+// of the numbers of choices is an unbiased estimate of the count. false is returned for an estimate.
+func countAssignments(allowed [][]bool, random glogic.Randomizer) (float64, bool) {
+
+	size := len(allowed)
+	if size <= maxExactDrawSize {
+		return float64(newAssignmentCounter(allowed).count(0)), true
+	}
+	total := 0.0
+	for sample := 0; sample < previewSamples; sample++ {
+		used := make([]bool, size)
+		product := 1.0
+		for giver := 0; giver < size && product > 0; giver++ {
+			choices := make([]int, 0, size)
+			for receiver, ok := range allowed[giver] {
+				if ok && !used[receiver] {
+					choices = append(choices, receiver)
+				}
+			}
+			if len(choices) == 0 {
+				product = 0
+				break
+			}
+			used[choices[random.NextInt(len(choices))]] = true
+			product *= float64(len(choices))
+		}
+		total += product
+	}
+	return total / previewSamples, false
+}
+
+// singleCycleProbability returns the share of the valid assignments forming a single circle.
+// Up to maxExactCycleSize players the circles are counted exactly, above the share is estimated from drawn assignments.
+func singleCycleProbability(allowed [][]bool, assignments float64, random glogic.Randomizer) float64 {
+
+	size := len(allowed)
+	if size == 0 || assignments == 0 {
+		return 0
+	}
+	if size <= maxExactCycleSize {
+		return float64(newCycleCounter(allowed).count(1, 0)) / assignments
+	}
+	cycles := 0
+	for sample := 0; sample < previewSamples; sample++ {
+		assignment, ok := sampleAssignment(allowed, random)
+		if !ok {
+			return 0
+		}
+		length := 1
+		for current := assignment[0]; current != 0; current = assignment[current] {
+			length++
+		}
+		if length == size {
+			cycles++
+		}
+	}
+	return float64(cycles) / previewSamples
+}
+
+// constrainedPlayers returns the players with the fewest possible receivers or givers, the most constrained first
+func constrainedPlayers(input to.DrawInputTo) []to.ConstrainedPlayerTo {
+
+	players := make([]to.ConstrainedPlayerTo, len(input.Players))
+	for i, name := range input.Players {
+		players[i].Name = name
+	}
+	for giver := range input.Allowed {
+		for receiver, ok := range input.Allowed[giver] {
+			if ok {
+				players[giver].Receivers++
+				players[receiver].Givers++
+			}
+		}
+	}
+	options := func(player to.ConstrainedPlayerTo) int {
+		if player.Givers < player.Receivers {
+			return player.Givers
+		}
+		return player.Receivers
+	}
+	sort.SliceStable(players, func(i, j int) bool {
+		return options(players[i]) < options(players[j])
+	})
+	if len(players) > maxConstrainedPlayers {
+		players = players[:maxConstrainedPlayers]
+	}
+	return players
+}
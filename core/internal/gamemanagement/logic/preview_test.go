@@ -0,0 +1,68 @@
+package logic_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+)
+
+var _ = Describe("PreviewDraw", func() {
+
+	var gamemanagement logic.Gamemanagement
+	var mock sqlmock.Sqlmock
+	code := "ABC"
+
+	BeforeEach(func() {
+		var c dataaccess.Connection
+		c, mock = dataaccess.NewMockConnection()
+		gamemanagement = newTestGamemanagement(c, nil, nil)
+	})
+
+	expectPreviewQueries := func(drawMode string, exceptions *sqlmock.Rows) {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "draw_mode"}).AddRow(1, code, "Ready", drawMode))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready").AddRow(4, "Strolch", 1, "Ready"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(exceptions)
+	}
+
+	It("should count the assignments and circles without saving lots", func() {
+		expectPreviewQueries("Free", sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+		drawPreviewTo, err := gamemanagement.PreviewDraw(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drawPreviewTo.Feasible).To(BeTrue())
+		Expect(drawPreviewTo.Exact).To(BeTrue())
+		// 9 derangements of 4 players, 6 of them are a single circle
+		Expect(drawPreviewTo.ValidAssignments).To(BeNumerically("==", 9))
+		Expect(drawPreviewTo.SingleCycleProbability).To(BeNumerically("~", 6.0/9.0, 1e-9))
+		Expect(drawPreviewTo.ConstrainedPlayers).To(HaveLen(3))
+	})
+	It("should list the most constrained players first", func() {
+		expectPreviewQueries("Free", sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 3, 1).AddRow(2, 3, 2))
+		mock.ExpectQuery("SELECT").WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Susi"))
+		mock.ExpectQuery("SELECT").WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max").AddRow(2, "Moritz"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+		drawPreviewTo, err := gamemanagement.PreviewDraw(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drawPreviewTo.Feasible).To(BeTrue())
+		Expect(drawPreviewTo.ConstrainedPlayers[0]).To(Equal(to.ConstrainedPlayerTo{Name: "Susi", Receivers: 1, Givers: 3}))
+		Expect(drawPreviewTo.ValidAssignments).To(BeNumerically("==", 3))
+	})
+	It("should report an infeasible draw", func() {
+		expectPreviewQueries("SingleCycle", sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 1, 2).AddRow(2, 1, 3).AddRow(3, 1, 4))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Max"))
+		mock.ExpectQuery("SELECT").WithArgs(2, 3, 4).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Moritz").AddRow(3, "Susi").AddRow(4, "Strolch"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+		drawPreviewTo, err := gamemanagement.PreviewDraw(code)
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drawPreviewTo.Feasible).To(BeFalse())
+		Expect(drawPreviewTo.Message).ToNot(BeEmpty())
+		Expect(drawPreviewTo.ValidAssignments).To(BeZero())
+		Expect(drawPreviewTo.SingleCycleProbability).To(BeZero())
+	})
+})
@@ -0,0 +1,201 @@
+package logic
+
+import (
+	"sort"
+
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+	glogic "github.com/yoktobit/secretsanta/internal/general/logic"
+)
+
+// maxRepairExchanges is how many pairs of other players may additionally exchange receivers to repair the lots,
+// if the organizer allowed it
+const maxRepairExchanges = 2
+
+// repairAssignment closes the gaps in an assignment: every open giver lacks one receiver and every open receiver one giver.
+// First only the open givers get the open receivers. If that breaks the input and maxExchanges is above 0, up to
+// maxExchanges other pairs are included and exchange their receivers too, as few as possible.
+// The repaired assignment and the givers whose receivers changed are returned.
+func repairAssignment(input to.DrawInputTo, assignment [][]int, openGivers []int, openReceivers []int, maxExchanges int, random glogic.Randomizer) ([][]int, []int, bool) {
+
+	pairs := make([][2]int, 0)
+	for giver, receivers := range assignment {
+		for _, receiver := range receivers {
+			pairs = append(pairs, [2]int{giver, receiver})
+		}
+	}
+	// the pairs are tried in random order, so the repair does not tell anything about the other lots
+	random.Shuffle(len(pairs), func(i int, j int) {
+		pairs[i], pairs[j] = pairs[j], pairs[i]
+	})
+	for exchanges := 0; exchanges <= maxExchanges && exchanges <= len(pairs); exchanges++ {
+		var repaired [][]int
+		found := forEachCombination(len(pairs), exchanges, func(chosen []int) bool {
+			givers := append([]int(nil), openGivers...)
+			receivers := append([]int(nil), openReceivers...)
+			candidate := copyAssignment(assignment)
+			for _, i := range chosen {
+				giver, receiver := pairs[i][0], pairs[i][1]
+				givers = append(givers, giver)
+				receivers = append(receivers, receiver)
+				candidate[giver] = removeReceiver(candidate[giver], receiver)
+			}
+			random.Shuffle(len(receivers), func(i int, j int) {
+				receivers[i], receivers[j] = receivers[j], receivers[i]
+			})
+			return forEachPermutation(receivers, func(permutation []int) bool {
+				attempt := copyAssignment(candidate)
+				for i, giver := range givers {
+					attempt[giver] = append(attempt[giver], permutation[i])
+				}
+				for giver := range attempt {
+					sort.Ints(attempt[giver])
+				}
+				if checkAssignment(input, attempt) {
+					repaired = attempt
+					return true
+				}
+				return false
+			})
+		})
+		if found {
+			return repaired, changedGivers(assignment, repaired, openGivers), true
+		}
+	}
+	return nil, nil, false
+}
+
+// changedGivers returns the givers whose receivers differ between before and after, open givers always changed
+func changedGivers(before [][]int, after [][]int, openGivers []int) []int {
+
+	changed := make(map[int]bool)
+	for _, giver := range openGivers {
+		changed[giver] = true
+	}
+	for giver := range after {
+		kept := make(map[int]bool)
+		for _, receiver := range before[giver] {
+			kept[receiver] = true
+		}
+		for _, receiver := range after[giver] {
+			if !kept[receiver] {
+				changed[giver] = true
+			}
+		}
+	}
+	givers := make([]int, 0, len(changed))
+	for giver := range changed {
+		givers = append(givers, giver)
+	}
+	sort.Ints(givers)
+	return givers
+}
+
+// forEachCombination calls f with every set of size indices out of 0..count-1 until f returns true
+func forEachCombination(count int, size int, f func(chosen []int) bool) bool {
+
+	chosen := make([]int, 0, size)
+	var extend func(start int) bool
+	extend = func(start int) bool {
+		if len(chosen) == size {
+			return f(chosen)
+		}
+		for i := start; i < count; i++ {
+			chosen = append(chosen, i)
+			if extend(i + 1) {
+				return true
+			}
+			chosen = chosen[:len(chosen)-1]
+		}
+		return false
+	}
+	return extend(0)
+}
+
+// forEachPermutation calls f with every order of values until f returns true
+func forEachPermutation(values []int, f func(permutation []int) bool) bool {
+
+	permutation := make([]int, 0, len(values))
+	used := make([]bool, len(values))
+	var extend func() bool
+	extend = func() bool {
+		if len(permutation) == len(values) {
+			return f(permutation)
+		}
+		for i, value := range values {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			permutation = append(permutation, value)
+			if extend() {
+				return true
+			}
+			permutation = permutation[:len(permutation)-1]
+			used[i] = false
+		}
+		return false
+	}
+	return extend()
+}
+
+func copyAssignment(assignment [][]int) [][]int {
+
+	copied := make([][]int, len(assignment))
+	for giver, receivers := range assignment {
+		copied[giver] = append([]int(nil), receivers...)
+	}
+	return copied
+}
+
+func removeReceiver(receivers []int, receiver int) []int {
+
+	for i, value := range receivers {
+		if value == receiver {
+			return append(receivers[:i:i], receivers[i+1:]...)
+		}
+	}
+	return receivers
+}
+
+// spliceAssignment lets a new player join an assignment without him/her. For every gift one pair X→Y is broken
+// and becomes X→New→Y, so only the givers X get other receivers. Their receivers Y are not told who gifts them,
+// so they need not know. The pairs are tried in random order, the spliced assignment and the givers X are returned.
+func spliceAssignment(input to.DrawInputTo, assignment [][]int, newPlayer int, random glogic.Randomizer) ([][]int, []int, bool) {
+
+	pairs := make([][2]int, 0)
+	for giver, receivers := range assignment {
+		for _, receiver := range receivers {
+			pairs = append(pairs, [2]int{giver, receiver})
+		}
+	}
+	random.Shuffle(len(pairs), func(i int, j int) {
+		pairs[i], pairs[j] = pairs[j], pairs[i]
+	})
+	var spliced [][]int
+	var givers []int
+	found := forEachCombination(len(pairs), input.GiftsPerPlayer, func(chosen []int) bool {
+		candidate := copyAssignment(assignment)
+		candidate[newPlayer] = make([]int, 0, len(chosen))
+		breakers := make([]int, 0, len(chosen))
+		for _, i := range chosen {
+			giver, receiver := pairs[i][0], pairs[i][1]
+			candidate[giver] = append(removeReceiver(candidate[giver], receiver), newPlayer)
+			candidate[newPlayer] = append(candidate[newPlayer], receiver)
+			breakers = append(breakers, giver)
+		}
+		for giver := range candidate {
+			sort.Ints(candidate[giver])
+		}
+		if checkAssignment(input, candidate) {
+			spliced = candidate
+			givers = breakers
+			return true
+		}
+		return false
+	})
+	if !found {
+		return nil, nil, false
+	}
+	sort.Ints(givers)
+	return spliced, givers, true
+}
@@ -0,0 +1,238 @@
+package logic_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/errors"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+)
+
+var _ = Describe("RemovePlayerFromDrawnGame", func() {
+
+	var gamemanagement logic.Gamemanagement
+	var mock sqlmock.Sqlmock
+	code := "ABC"
+
+	BeforeEach(func() {
+		var c dataaccess.Connection
+		c, mock = dataaccess.NewMockConnection()
+		gamemanagement = newTestGamemanagement(c, nil, nil)
+	})
+
+	// expectDrawnGame expects the queries for four players drawn in the circle Max→Moritz→Susi→Strolch→Max, Susi drops out
+	expectDrawnGame := func(forbidMutualPairs bool, exceptions *sqlmock.Rows) {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "forbid_mutual_pairs"}).AddRow(1, code, "Drawn", forbidMutualPairs))
+		mock.ExpectQuery("SELECT").WithArgs("susi", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(3, "Susi", 1))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready").AddRow(4, "Strolch", 1, "Ready"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(exceptions)
+	}
+	expectAssignments := func() {
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "giver_id", "receiver_id"}).AddRow(1, 1, 1, 2).AddRow(2, 1, 2, 3).AddRow(3, 1, 3, 4).AddRow(4, 1, 4, 1))
+	}
+
+	It("should only give the receiver to the giver of the removed player", func() {
+		var repairs driver.Value
+		expectDrawnGame(false, sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+		expectAssignments()
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "algorithm_version", "seed", "commitment", "input", "result"}).AddRow(1, 1, "1", 42, "abc", "{}", "[[1],[2],[3],[0]]"))
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 3, 3).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, 4).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(true, sqlmock.AnyArg(), 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 3, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 3, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), "susi", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "1", 42, "abc", "{}", "[[1],[2],[3],[0]]", captureArgument{&repairs}, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		repairDrawResponseTo, err := gamemanagement.RemovePlayerFromDrawnGame(to.DropOutPlayerTo{Name: "Susi", GameCode: code})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repairDrawResponseTo.Ok).To(BeTrue())
+		Expect(repairDrawResponseTo.Changed).To(Equal(1))
+		// Max→Moritz→Strolch→Max after Susi dropped out
+		Expect(repairs).To(MatchRegexp(`^\[\{"kind":"dropOut","player":"Susi","repairedAt":"[^"]+","players":\["Max","Moritz","Strolch"\],"result":\[\[1\],\[2\],\[0\]\]\}\]$`))
+	})
+	It("should only let other players exchange their receivers if the organizer allowed it", func() {
+		expectDrawnGame(false, sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 2, 4))
+		mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Moritz"))
+		mock.ExpectQuery("SELECT").WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(4, "Strolch"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+		expectAssignments()
+		repairDrawResponseTo, err := gamemanagement.RemovePlayerFromDrawnGame(to.DropOutPlayerTo{Name: "Susi", GameCode: code})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repairDrawResponseTo.Ok).To(BeFalse())
+		Expect(repairDrawResponseTo.Message).To(ContainSubstring("tauschen"))
+		Expect(repairDrawResponseTo.Changed).To(BeZero())
+	})
+	It("should change as few other lots as the exceptions allow if the organizer allowed it", func() {
+		expectDrawnGame(false, sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}).AddRow(1, 2, 4))
+		mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Moritz"))
+		mock.ExpectQuery("SELECT").WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(4, "Strolch"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+		expectAssignments()
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 3, 3).WillReturnResult(sqlmock.NewResult(0, 2))
+		// Max→Strolch, Moritz→Max, Strolch→Moritz is the only repair
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 1, 4).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(true, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 2, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+		mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(true, sqlmock.AnyArg(), 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 4).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("INSERT").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 4, 2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(true, sqlmock.AnyArg(), 4).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 3, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 3, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), "susi", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		repairDrawResponseTo, err := gamemanagement.RemovePlayerFromDrawnGame(to.DropOutPlayerTo{Name: "Susi", GameCode: code, ExchangeOthers: true})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repairDrawResponseTo.Ok).To(BeTrue())
+		Expect(repairDrawResponseTo.Changed).To(Equal(3))
+	})
+	It("should keep the lots if they can not be repaired", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "forbid_mutual_pairs"}).AddRow(1, code, "Drawn", true))
+		mock.ExpectQuery("SELECT").WithArgs("susi", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id"}).AddRow(3, "Susi", 1))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "giver_id", "receiver_id"}).AddRow(1, 1, 1, 2).AddRow(2, 1, 2, 3).AddRow(3, 1, 3, 1))
+		repairDrawResponseTo, err := gamemanagement.RemovePlayerFromDrawnGame(to.DropOutPlayerTo{Name: "Susi", GameCode: code})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repairDrawResponseTo.Ok).To(BeFalse())
+		Expect(repairDrawResponseTo.Message).ToNot(BeEmpty())
+		Expect(repairDrawResponseTo.Changed).To(BeZero())
+	})
+	It("should fail for a game which has not been drawn", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+		_, err := gamemanagement.RemovePlayerFromDrawnGame(to.DropOutPlayerTo{Name: "Susi", GameCode: code})
+		Expect(err).To(MatchError(errors.ErrGameNotDrawn))
+	})
+	It("should not simply remove a player from a drawn game", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Drawn"))
+		err := gamemanagement.RemovePlayerFromGame(to.AddRemovePlayerTo{Name: "Susi", GameCode: code})
+		Expect(err).To(MatchError(errors.ErrGameAlreadyDrawn))
+	})
+})
+
+var _ = Describe("AddPlayerToDrawnGame", func() {
+
+	var gamemanagement logic.Gamemanagement
+	var mock sqlmock.Sqlmock
+	code := "ABC"
+
+	BeforeEach(func() {
+		var c dataaccess.Connection
+		c, mock = dataaccess.NewMockConnection()
+		gamemanagement = newTestGamemanagement(c, nil, nil)
+	})
+
+	// expectDrawnGame expects the queries for three players drawn in the circle Max→Moritz→Susi→Max
+	expectDrawnGame := func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Drawn"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name", "role"}).AddRow(1, "Max", "Max", "Admin").AddRow(2, "Moritz", "Moritz", "Player").AddRow(3, "Susi", "Susi", "Player"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "status"}).AddRow(1, "Max", 1, "Ready").AddRow(2, "Moritz", 1, "Ready").AddRow(3, "Susi", 1, "Ready"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id"}))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "player_a_id", "player_b_id", "weight"}))
+	}
+
+	It("should splice the new player into one pair", func() {
+		expectDrawnGame()
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "giver_id", "receiver_id"}).AddRow(1, 1, 1, 2).AddRow(2, 1, 2, 3).AddRow(3, 1, 3, 1))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "algorithm_version", "seed", "commitment", "input", "result", "repairs"}).AddRow(1, 1, "1", 42, "abc", "{}", "[[1],[2],[0]]", "[]"))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "players"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Strolch", "Strolch", "strolch", "", 1, "", "Player", nil, false).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectExec(`DELETE FROM "invites" WHERE player_id = \$1`).WithArgs(4).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`INSERT INTO "invites"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 4, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`INSERT INTO "players"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectQuery(`INSERT INTO "players"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery(`INSERT INTO "player_exceptions"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 4, 2, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`INSERT INTO "players"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectQuery(`INSERT INTO "players"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectQuery(`INSERT INTO "player_exceptions"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 4, 3, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		// Strolch may only gift Max, so Susi→Max becomes Susi→Strolch→Max
+		mock.ExpectExec("UPDATE").WithArgs(sqlmock.AnyArg(), 3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE "players" SET "receivers_changed"`).WithArgs(true, sqlmock.AnyArg(), 3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO "assignments"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 3, 4).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectQuery(`INSERT INTO "assignments"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 4, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectExec(`UPDATE "draw_audits"`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "1", 42, "abc", "{}", "[[1],[2],[0]]", sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		repairDrawResponseTo, err := gamemanagement.AddPlayerToDrawnGame(to.LateJoinPlayerTo{Name: "Strolch", GameCode: code, NotGifting: []string{"Moritz", "Susi"}})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repairDrawResponseTo.Invite.Name).To(Equal("Strolch"))
+		Expect(repairDrawResponseTo.Invite.Token).ToNot(BeEmpty())
+		Expect(repairDrawResponseTo.Ok).To(BeTrue())
+		Expect(repairDrawResponseTo.Changed).To(Equal(1))
+	})
+	It("should keep the lots if the new player can not be spliced in", func() {
+		expectDrawnGame()
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "giver_id", "receiver_id"}).AddRow(1, 1, 1, 2).AddRow(2, 1, 2, 3).AddRow(3, 1, 3, 1))
+		repairDrawResponseTo, err := gamemanagement.AddPlayerToDrawnGame(to.LateJoinPlayerTo{Name: "Strolch", GameCode: code, NotGifting: []string{"Moritz", "Susi"}, NotGiftedBy: []string{"Susi"}})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repairDrawResponseTo.Ok).To(BeFalse())
+		Expect(repairDrawResponseTo.Message).ToNot(BeEmpty())
+		Expect(repairDrawResponseTo.Changed).To(BeZero())
+	})
+	It("should fail if the transaction can not be started", func() {
+		expectDrawnGame()
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "giver_id", "receiver_id"}).AddRow(1, 1, 1, 2).AddRow(2, 1, 2, 3).AddRow(3, 1, 3, 1))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "game_id", "algorithm_version", "seed", "commitment", "input", "result", "repairs"}).AddRow(1, 1, "1", 42, "abc", "{}", "[[1],[2],[0]]", "[]"))
+		mock.ExpectBegin().WillReturnError(sql.ErrConnDone)
+		repairDrawResponseTo, err := gamemanagement.AddPlayerToDrawnGame(to.LateJoinPlayerTo{Name: "Strolch", GameCode: code, NotGifting: []string{"Moritz", "Susi"}})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(sql.ErrConnDone))
+		Expect(repairDrawResponseTo.Ok).To(BeFalse())
+		Expect(repairDrawResponseTo.Invite).To(BeNil())
+	})
+	It("should fail for a name which is already taken", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Drawn"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name", "game_id", "role"}).AddRow(3, "Susi", "Susi", 1, "Player"))
+		_, err := gamemanagement.AddPlayerToDrawnGame(to.LateJoinPlayerTo{Name: "Susi", GameCode: code})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(errors.ErrPlayerAlreadyExists))
+	})
+	It("should fail for the name of an organizer, who is not part of the draw", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Drawn"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name", "game_id", "role"}).AddRow(3, "Susi", "Susi", 1, "Player").AddRow(5, "Oma", "Oma", 1, "Organizer"))
+		_, err := gamemanagement.AddPlayerToDrawnGame(to.LateJoinPlayerTo{Name: "OMA", GameCode: code})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(errors.ErrPlayerAlreadyExists))
+	})
+	It("should fail for the name another player is shown with", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Drawn"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name", "game_id", "role"}).AddRow(3, "Susi", "Strolch", 1, "Player"))
+		_, err := gamemanagement.AddPlayerToDrawnGame(to.LateJoinPlayerTo{Name: "strolch", GameCode: code})
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(err).To(MatchError(errors.ErrPlayerAlreadyExists))
+	})
+	It("should fail for an unknown player in the exceptions", func() {
+		expectDrawnGame()
+		_, err := gamemanagement.AddPlayerToDrawnGame(to.LateJoinPlayerTo{Name: "Strolch", GameCode: code, NotGifting: []string{"Bello"}})
+		Expect(err).To(MatchError(errors.ErrPlayerNotFound))
+	})
+	It("should fail for a game which has not been drawn", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+		_, err := gamemanagement.AddPlayerToDrawnGame(to.LateJoinPlayerTo{Name: "Strolch", GameCode: code})
+		Expect(err).To(MatchError(errors.ErrGameNotDrawn))
+	})
+	It("should not simply add a player to a drawn game", func() {
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Drawn"))
+		_, err := gamemanagement.AddPlayerToGame(to.AddRemovePlayerTo{Name: "Strolch", GameCode: code})
+		Expect(err).To(MatchError(errors.ErrGameAlreadyDrawn))
+	})
+})
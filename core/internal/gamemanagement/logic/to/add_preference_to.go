@@ -0,0 +1,9 @@
+package to
+
+// AddPreferenceTo gibt eine weiche Ausnahme im Spiel an, NameA soll NameB möglichst nicht beschenken
+type AddPreferenceTo struct {
+	NameA    string `json:"nameA" validate:"required"`
+	NameB    string `json:"nameB" validate:"required"`
+	Weight   int    `json:"weight" validate:"min=1"`
+	GameCode string `json:"gameCode" validate:"required"`
+}
@@ -0,0 +1,7 @@
+package to
+
+// AddRemoveHouseholdTo is for adding a new household to a game or removing it
+type AddRemoveHouseholdTo struct {
+	Name     string `json:"name" validate:"required"`
+	GameCode string `json:"gameCode" validate:"required"`
+}
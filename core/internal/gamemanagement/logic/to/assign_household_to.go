@@ -0,0 +1,8 @@
+package to
+
+// AssignHouseholdTo puts a player into a household, an empty household name removes the player from its household
+type AssignHouseholdTo struct {
+	PlayerName    string `json:"playerName" validate:"required"`
+	HouseholdName string `json:"householdName"`
+	GameCode      string `json:"gameCode" validate:"required"`
+}
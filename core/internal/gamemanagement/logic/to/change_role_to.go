@@ -0,0 +1,7 @@
+package to
+
+// ChangeRoleTo ist zum Ernennen oder Absetzen eines weiteren Spielleiters
+type ChangeRoleTo struct {
+	Name     string `json:"name" validate:"required"`
+	GameCode string `json:"gameCode" validate:"required"`
+}
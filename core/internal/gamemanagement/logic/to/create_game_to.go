@@ -1,9 +1,24 @@
 package to
 
+import "time"
+
 // CreateGameTo is for creating a new game
 type CreateGameTo struct {
-	Title         string `json:"title" validate:"required"`
-	Description   string `json:"description"`
-	AdminUser     string `json:"adminUser" validate:"required"`
-	AdminPassword string `json:"adminPassword" validate:"required"`
+	Title             string     `json:"title" validate:"required"`
+	Description       string     `json:"description"`
+	AdminUser         string     `json:"adminUser" validate:"required"`
+	AdminPassword     string     `json:"adminPassword" validate:"required"`
+	DrawMode          string     `json:"drawMode" validate:"omitempty,oneof=Free SingleCycle"`
+	ForbidMutualPairs bool       `json:"forbidMutualPairs"`
+	PreviousGameCode  string     `json:"previousGameCode"`
+	AvoidRepeatYears  int        `json:"avoidRepeatYears" validate:"min=0"`
+	GiftsPerPlayer    int        `json:"giftsPerPlayer" validate:"omitempty,min=1"`
+	EventDate         *time.Time `json:"eventDate"`
+	Budget            string     `json:"budget"`
+	// DrawStrategy is the name of a registered draw strategy, empty for the default strategy
+	DrawStrategy string `json:"drawStrategy"`
+	// OrganizerOnly lets the admin organize the game without taking part in the draw
+	OrganizerOnly bool `json:"organizerOnly"`
+	// PreviousGamePassword is the password of the admin in the previous game, it proves that the admin manages both games
+	PreviousGamePassword string `json:"previousGamePassword" validate:"required_with=PreviousGameCode"`
 }
@@ -0,0 +1,18 @@
+package to
+
+import "time"
+
+// DrawAuditTo describes how the lots of a game were drawn. Until the event date has passed,
+// only the commitment is published, afterwards Seed, Input and Result allow to verify the draw.
+type DrawAuditTo struct {
+	AlgorithmVersion string      `json:"algorithmVersion"`
+	Commitment       string      `json:"commitment"`
+	DrawnAt          time.Time   `json:"drawnAt"`
+	Revealed         bool        `json:"revealed"`
+	Seed             int64       `json:"seed"`
+	Input            DrawInputTo `json:"input"`
+	// Result holds for every player of the input the indices of the players he/she gifts
+	Result [][]int `json:"result"`
+	// Repairs lists the changes of the lots after the draw, the oldest first
+	Repairs []DrawRepairTo `json:"repairs"`
+}
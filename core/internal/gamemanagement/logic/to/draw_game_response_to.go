@@ -4,4 +4,8 @@ package to
 type DrawGameResponseTo struct {
 	Ok      bool   `json:"ok"`
 	Message string `json:"message"`
+	// Score is the sum of the weights of all preferences which could not be fulfilled, 0 is best
+	Score int `json:"score"`
+	// Commitment is the hash of the seed and input of the draw, it allows to verify the draw after the event
+	Commitment string `json:"commitment"`
 }
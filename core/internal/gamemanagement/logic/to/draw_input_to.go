@@ -0,0 +1,16 @@
+package to
+
+// DrawInputTo is everything a draw depends on besides its seed. Players are referred to by their index in Players.
+type DrawInputTo struct {
+	Players []string `json:"players"`
+	// Allowed tells for every giver and receiver whether the pair is allowed by self gifts, exceptions and households
+	Allowed [][]bool `json:"allowed"`
+	// History holds the [giver, receiver] pairs of every previous year which should not be repeated, last year first
+	History           [][][2]int `json:"history"`
+	Penalty           [][]int    `json:"penalty"`
+	GiftsPerPlayer    int        `json:"giftsPerPlayer"`
+	SingleCycle       bool       `json:"singleCycle"`
+	ForbidMutualPairs bool       `json:"forbidMutualPairs"`
+	// Strategy is the name of the draw strategy, empty for the default strategy
+	Strategy string `json:"strategy,omitempty"`
+}
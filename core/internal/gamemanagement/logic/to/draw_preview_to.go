@@ -0,0 +1,23 @@
+package to
+
+// DrawPreviewTo describes how a game would be drawn without revealing any pairs
+type DrawPreviewTo struct {
+	Feasible bool   `json:"feasible"`
+	Message  string `json:"message"`
+	// Score is the lowest total penalty of the preferences found by a trial draw
+	Score int `json:"score"`
+	// ValidAssignments is the number of assignments with one gift per player allowed by exceptions and households,
+	// it is estimated if Exact is false
+	ValidAssignments float64 `json:"validAssignments"`
+	Exact            bool    `json:"exact"`
+	// SingleCycleProbability is the probability that a free draw forms a single circle
+	SingleCycleProbability float64               `json:"singleCycleProbability"`
+	ConstrainedPlayers     []ConstrainedPlayerTo `json:"constrainedPlayers"`
+}
+
+// ConstrainedPlayerTo tells how many players a player may gift and may be gifted by
+type ConstrainedPlayerTo struct {
+	Name      string `json:"name"`
+	Receivers int    `json:"receivers"`
+	Givers    int    `json:"givers"`
+}
@@ -0,0 +1,14 @@
+package to
+
+import "time"
+
+// DrawRepairTo records a repair of the lots after the draw, because Player dropped out or joined late.
+// Players and Result describe all lots after the repair, they are only revealed together with the draw.
+type DrawRepairTo struct {
+	Kind       string    `json:"kind"`
+	Player     string    `json:"player"`
+	RepairedAt time.Time `json:"repairedAt"`
+	Players    []string  `json:"players,omitempty"`
+	// Result holds for every player of Players the indices of the players he/she gifts
+	Result [][]int `json:"result,omitempty"`
+}
@@ -0,0 +1,10 @@
+package to
+
+// DropOutPlayerTo is for removing a player from a game which has already been drawn
+type DropOutPlayerTo struct {
+	Name     string `json:"name" validate:"required"`
+	GameCode string `json:"gameCode" validate:"required"`
+	// ExchangeOthers allows players who did not gift the dropped out player to exchange their receivers,
+	// if the exceptions leave no other way to repair the lots
+	ExchangeOthers bool `json:"exchangeOthers"`
+}
@@ -0,0 +1,7 @@
+package to
+
+// ErrorResponseTo describes why a request was refused
+type ErrorResponseTo struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
@@ -4,4 +4,7 @@ package to
 type ExceptionResponseTo struct {
 	NameA string `json:"nameA"`
 	NameB string `json:"nameB"`
+	// DisplayNameA and DisplayNameB are the names shown for the players, the login names identify them
+	DisplayNameA string `json:"displayNameA"`
+	DisplayNameB string `json:"displayNameB"`
 }
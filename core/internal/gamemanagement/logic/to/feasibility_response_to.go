@@ -0,0 +1,10 @@
+package to
+
+// FeasibilityResponseTo tells whether a game can be drawn and which players and exceptions prevent it
+type FeasibilityResponseTo struct {
+	Feasible   bool                  `json:"feasible"`
+	Message    string                `json:"message"`
+	Players    []string              `json:"players"`
+	Exceptions []ExceptionResponseTo `json:"exceptions"`
+	Households []string              `json:"households"`
+}
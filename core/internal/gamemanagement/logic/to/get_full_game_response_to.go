@@ -1,10 +1,16 @@
 package to
 
+import "time"
+
 // GetFullGameResponseTo gibt Spielinfos zurück
 type GetFullGameResponseTo struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	Gifted      string `json:"gifted"`
-	Code        string `json:"code"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Gifted      []string   `json:"gifted"`
+	Code        string     `json:"code"`
+	Budget      string     `json:"budget"`
+	EventDate   *time.Time `json:"eventDate"`
+	// ReceiversChanged tells the player that a repair of the lots gave him/her other receivers since the draw
+	ReceiversChanged bool `json:"receiversChanged"`
 }
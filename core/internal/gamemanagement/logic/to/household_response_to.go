@@ -0,0 +1,9 @@
+package to
+
+// HouseholdResponseTo describes a household and its members
+type HouseholdResponseTo struct {
+	Name    string   `json:"name"`
+	Players []string `json:"players"`
+	// DisplayNames are the names shown for the players, in the same order as their login names
+	DisplayNames []string `json:"displayNames"`
+}
@@ -0,0 +1,7 @@
+package to
+
+// InvitePlayerTo ist zum Ausstellen oder Widerrufen der Einladung eines Spielers
+type InvitePlayerTo struct {
+	Name     string `json:"name" validate:"required"`
+	GameCode string `json:"gameCode" validate:"required"`
+}
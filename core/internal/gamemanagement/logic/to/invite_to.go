@@ -0,0 +1,10 @@
+package to
+
+import "time"
+
+// InviteTo is the invite of a player. The token is only shown once, when the invite is created.
+type InviteTo struct {
+	Name      string    `json:"name"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
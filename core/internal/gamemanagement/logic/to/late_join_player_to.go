@@ -0,0 +1,12 @@
+package to
+
+// LateJoinPlayerTo is for adding a new player to a game which has already been drawn.
+// The exceptions of the new player have to be known before the lots are changed.
+type LateJoinPlayerTo struct {
+	Name     string `json:"name" validate:"required"`
+	GameCode string `json:"gameCode" validate:"required"`
+	// NotGifting are the players the new player doesnt have to gift
+	NotGifting []string `json:"notGifting"`
+	// NotGiftedBy are the players who dont have to gift the new player
+	NotGiftedBy []string `json:"notGiftedBy"`
+}
@@ -2,6 +2,7 @@ package to
 
 // PlayerResponseTo describes properties of a player
 type PlayerResponseTo struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Status      string `json:"status"`
 }
@@ -0,0 +1,11 @@
+package to
+
+// PreferenceResponseTo gibt eine weiche Ausnahme des Spiels zurück
+type PreferenceResponseTo struct {
+	NameA  string `json:"nameA"`
+	NameB  string `json:"nameB"`
+	Weight int    `json:"weight"`
+	// DisplayNameA and DisplayNameB are the names shown for the players, the login names identify them
+	DisplayNameA string `json:"displayNameA"`
+	DisplayNameB string `json:"displayNameB"`
+}
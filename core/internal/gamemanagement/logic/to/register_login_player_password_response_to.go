@@ -4,4 +4,6 @@ package to
 type RegisterLoginPlayerPasswordResponseTo struct {
 	Ok      bool   `json:"ok"`
 	Message string `json:"message"`
+	// RetryAfter sind die Sekunden, die nach zu vielen Fehlversuchen bis zum nächsten Versuch gewartet werden muss
+	RetryAfter int `json:"retryAfter,omitempty"`
 }
@@ -5,4 +5,6 @@ type RegisterLoginPlayerPasswordTo struct {
 	GameCode string `json:"gameCode" validate:"required"`
 	Name     string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
+	// InviteToken ist der Token aus dem Einladungslink, er wird nur zum Registrieren gebraucht
+	InviteToken string `json:"inviteToken"`
 }
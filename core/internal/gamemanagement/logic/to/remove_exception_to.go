@@ -0,0 +1,8 @@
+package to
+
+// RemoveExceptionTo entfernt eine Ausnahme aus dem Spiel
+type RemoveExceptionTo struct {
+	NameA    string `json:"nameA" validate:"required"`
+	NameB    string `json:"nameB" validate:"required"`
+	GameCode string `json:"gameCode" validate:"required"`
+}
@@ -0,0 +1,8 @@
+package to
+
+// RemovePreferenceTo entfernt eine weiche Ausnahme aus dem Spiel
+type RemovePreferenceTo struct {
+	NameA    string `json:"nameA" validate:"required"`
+	NameB    string `json:"nameB" validate:"required"`
+	GameCode string `json:"gameCode" validate:"required"`
+}
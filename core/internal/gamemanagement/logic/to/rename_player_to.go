@@ -0,0 +1,8 @@
+package to
+
+// RenamePlayerTo ist zum Ändern des angezeigten Namens eines Spielers, der Login-Name bleibt erhalten
+type RenamePlayerTo struct {
+	Name        string `json:"name" validate:"required"`
+	DisplayName string `json:"displayName" validate:"required"`
+	GameCode    string `json:"gameCode" validate:"required"`
+}
@@ -0,0 +1,12 @@
+package to
+
+// RepairDrawResponseTo is the answer sent after the lots of a drawn game have been repaired.
+// Changed is how many players got other receivers, they see it in their own game. Who they are stays secret,
+// because a giver who lost the dropped out player as receiver tells who gifted him/her.
+type RepairDrawResponseTo struct {
+	Ok      bool   `json:"ok"`
+	Message string `json:"message"`
+	Changed int    `json:"changed"`
+	// Invite is the invite of a player who joined late
+	Invite *InviteTo `json:"invite,omitempty"`
+}
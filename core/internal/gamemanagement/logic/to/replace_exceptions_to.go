@@ -0,0 +1,9 @@
+package to
+
+// ReplaceExceptionsTo ersetzt alle Ausnahmen des Spiels durch eine Ausschlussmatrix.
+// Excluded[i][j] gibt an, dass Players[i] Players[j] nicht beschenken muss.
+type ReplaceExceptionsTo struct {
+	GameCode string   `json:"gameCode" validate:"required"`
+	Players  []string `json:"players" validate:"dive,required"`
+	Excluded [][]bool `json:"excluded"`
+}
@@ -0,0 +1,8 @@
+package to
+
+// TransferAdminTo ist zum Übergeben der Spielleitung, From verliert die Rolle und To bekommt sie
+type TransferAdminTo struct {
+	From     string `json:"from" validate:"required"`
+	To       string `json:"to" validate:"required"`
+	GameCode string `json:"gameCode" validate:"required"`
+}
@@ -0,0 +1,23 @@
+package to
+
+import "time"
+
+// UpdateGameSettingsTo is for changing the settings of an existing game, fields which are not set stay unchanged.
+// Title, description, event date and budget can always be changed, the draw options only before the draw.
+// PlayerName is the admin changing the settings, who has to give the password of the previous game to link it.
+type UpdateGameSettingsTo struct {
+	GameCode          string     `json:"gameCode" validate:"required"`
+	PlayerName        string     `json:"playerName"`
+	Title             *string    `json:"title" validate:"omitempty,min=1"`
+	Description       *string    `json:"description"`
+	Budget            *string    `json:"budget"`
+	DrawMode          *string    `json:"drawMode" validate:"omitempty,oneof=Free SingleCycle"`
+	ForbidMutualPairs *bool      `json:"forbidMutualPairs"`
+	PreviousGameCode  *string    `json:"previousGameCode"`
+	AvoidRepeatYears  *int       `json:"avoidRepeatYears" validate:"omitempty,min=0"`
+	GiftsPerPlayer    *int       `json:"giftsPerPlayer" validate:"omitempty,min=1"`
+	EventDate         *time.Time `json:"eventDate"`
+	DrawStrategy      *string    `json:"drawStrategy"`
+	// PreviousGamePassword is the password of the admin in the previous game
+	PreviousGamePassword string `json:"previousGamePassword"`
+}
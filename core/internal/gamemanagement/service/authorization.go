@@ -0,0 +1,115 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/dataaccess"
+	to "github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
+)
+
+// Permission is what the caller of a route needs
+type Permission int
+
+const (
+	// PermissionPublic routes can be called without being logged in to a game
+	PermissionPublic Permission = iota
+	// PermissionPlayer routes can be called by every player of the game
+	PermissionPlayer
+	// PermissionAdmin routes can only be called by the admins and organizers of the game
+	PermissionAdmin
+	// PermissionParticipant routes can only be called by the players who take part in the draw
+	PermissionParticipant
+)
+
+// routePermissions maps method and path of a route to its permission. Routes which are not listed are refused,
+// so a new route has to be listed here before anybody can call it.
+var routePermissions = map[string]Permission{
+	"POST /createNewGame":    PermissionPublic,
+	"POST /registerPlayer":   PermissionPublic,
+	"POST /loginPlayer":      PermissionPublic,
+	"POST /draw/verify":      PermissionPublic,
+	"GET /game/:gameCode":    PermissionPublic,
+	"GET /logout":            PermissionPublic,
+	"GET /status":            PermissionPublic,
+	"POST /addPlayer":        PermissionAdmin,
+	"POST /removePlayer":     PermissionAdmin,
+	"POST /dropOutPlayer":    PermissionAdmin,
+	"POST /lateJoinPlayer":   PermissionAdmin,
+	"POST /renamePlayer":     PermissionAdmin,
+	"POST /promotePlayer":    PermissionAdmin,
+	"POST /demotePlayer":     PermissionAdmin,
+	"POST /transferAdmin":    PermissionAdmin,
+	"POST /invite":           PermissionAdmin,
+	"POST /revokeInvite":     PermissionAdmin,
+	"POST /addException":     PermissionAdmin,
+	"POST /removeException":  PermissionAdmin,
+	"PUT /exceptions":        PermissionAdmin,
+	"GET /feasibility":       PermissionAdmin,
+	"POST /addHousehold":     PermissionAdmin,
+	"POST /removeHousehold":  PermissionAdmin,
+	"POST /assignHousehold":  PermissionAdmin,
+	"GET /draw":              PermissionAdmin,
+	"GET /draw/preview":      PermissionAdmin,
+	"GET /reset":             PermissionAdmin,
+	"GET /restore":           PermissionAdmin,
+	"PATCH /game":            PermissionAdmin,
+	"POST /addPreference":    PermissionAdmin,
+	"POST /removePreference": PermissionAdmin,
+	"GET /game":              PermissionPlayer,
+	"GET /players":           PermissionPlayer,
+	"GET /exceptions":        PermissionPlayer,
+	"GET /households":        PermissionPlayer,
+	"GET /preferences":       PermissionPlayer,
+	"GET /draw/audit":        PermissionParticipant,
+}
+
+// authorize loads the role of the logged in player and refuses the routes the player is not permitted to call
+func (restService *restService) authorize(basePath string) gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + strings.TrimPrefix(c.FullPath(), basePath)
+		permission, found := routePermissions[route]
+		if !found {
+			log.Warnln("Refused route without permission", route)
+			c.AbortWithStatusJSON(http.StatusForbidden, to.ErrorResponseTo{Error: "forbidden", Message: "Das darf niemand."})
+			return
+		}
+		if permission == PermissionPublic {
+			c.Next()
+			return
+		}
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		player := session.Get("player")
+		if gameCode == nil || player == nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, to.ErrorResponseTo{Error: "notLoggedIn", Message: "Bitte melde dich zuerst beim Spiel an."})
+			return
+		}
+		role, err := restService.gamemanagement.GetPlayerRoleByCodeAndName(gameCode.(string), player.(string))
+		if err == gorm.ErrRecordNotFound {
+			c.AbortWithStatusJSON(http.StatusForbidden, to.ErrorResponseTo{Error: "notLoggedIn", Message: "Du nimmst nicht mehr an diesem Spiel teil."})
+			return
+		}
+		if err != nil {
+			log.Error(err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if permission == PermissionAdmin && !dataaccess.CanManage(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, to.ErrorResponseTo{Error: "forbidden", Message: "Das darf nur die Spielleitung."})
+			return
+		}
+		if permission == PermissionParticipant && !dataaccess.TakesPart(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, to.ErrorResponseTo{Error: "forbidden", Message: "Das dürfen nur die Wichtel, die mitmachen."})
+			return
+		}
+		c.Set("role", role)
+		c.Next()
+	}
+}
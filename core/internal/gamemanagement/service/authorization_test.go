@@ -0,0 +1,123 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	da "github.com/yoktobit/secretsanta/internal/gamemanagement/dataaccess"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/logic"
+	"github.com/yoktobit/secretsanta/internal/gamemanagement/service"
+	"github.com/yoktobit/secretsanta/internal/general/dataaccess"
+	gl "github.com/yoktobit/secretsanta/internal/general/logic"
+)
+
+var _ = Describe("Authorization", func() {
+
+	var mock sqlmock.Sqlmock
+	var router *gin.Engine
+	var recorder *httptest.ResponseRecorder
+	var loggedIn map[string]string
+	code := "ABC"
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		var c dataaccess.Connection
+		c, mock = dataaccess.NewMockConnection()
+		gamemanagement := logic.NewGamemanagement(c, da.NewGameRepository(c), da.NewPlayerRepository(c), da.NewPlayerExceptionRepository(c), da.NewHouseholdRepository(c), da.NewAssignmentRepository(c), da.NewPlayerPreferenceRepository(c), da.NewDrawAuditRepository(c), da.NewDrawRoundRepository(c), da.NewInviteRepository(c), da.NewLoginAuditRepository(c), logic.NewMemoryLoginAttemptStore(), logic.NewDefaultDrawStrategies(), gl.NewMockRandomizer())
+		recorder = httptest.NewRecorder()
+		_, router = gin.CreateTestContext(recorder)
+		router.Use(sessions.Sessions("test", cookie.NewStore([]byte("test"))))
+		loggedIn = map[string]string{}
+		// the session of the caller is filled before the routes are authorized, as the login would have done
+		router.Use(func(c *gin.Context) {
+			session := sessions.Default(c)
+			for key, value := range loggedIn {
+				session.Set(key, value)
+			}
+		})
+		group := router.Group("/api")
+		service.NewRestService(gamemanagement).DefineRoutes(group)
+		group.GET("/unlisted", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+	})
+
+	logIn := func(role string) {
+		loggedIn["gameCode"] = code
+		loggedIn["player"] = "Max"
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+		mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "game_id", "role"}).AddRow(1, "Max", 1, role))
+	}
+	call := func(method string, path string) int {
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		return recorder.Code
+	}
+
+	It("should let everybody call a public route", func() {
+		Expect(call("GET", "/api/status")).To(Equal(http.StatusOK))
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+	})
+	It("should refuse a route which is not listed", func() {
+		logIn("Admin")
+		Expect(call("GET", "/api/unlisted")).To(Equal(http.StatusForbidden))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"forbidden"`))
+	})
+	It("should refuse a player route without login", func() {
+		Expect(call("GET", "/api/players")).To(Equal(http.StatusForbidden))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"notLoggedIn"`))
+	})
+	It("should refuse a player who no longer takes part in the game", func() {
+		loggedIn["gameCode"] = code
+		loggedIn["player"] = "Max"
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+		mock.ExpectQuery("SELECT").WithArgs("max", 1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		Expect(call("GET", "/api/players")).To(Equal(http.StatusForbidden))
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(recorder.Body.String()).To(ContainSubstring(`"notLoggedIn"`))
+	})
+	It("should let a player call a player route", func() {
+		logIn("Player")
+		mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "display_name", "status"}).AddRow(1, "Max", "Max", "Ready"))
+		Expect(call("GET", "/api/players")).To(Equal(http.StatusOK))
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+	})
+	It("should refuse an admin route to a player", func() {
+		logIn("Player")
+		Expect(call("GET", "/api/reset")).To(Equal(http.StatusForbidden))
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(recorder.Body.String()).To(ContainSubstring(`"forbidden"`))
+	})
+	for _, role := range []string{"Admin", "Organizer"} {
+		role := role
+		It("should let the role "+role+" call an admin route", func() {
+			logIn(role)
+			// the game has not been drawn, so the reset itself is refused after the authorization
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+			Expect(call("GET", "/api/reset")).To(Equal(http.StatusConflict))
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		})
+	}
+	It("should refuse a participant route to an organizer", func() {
+		logIn("Organizer")
+		Expect(call("GET", "/api/draw/audit")).To(Equal(http.StatusForbidden))
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		Expect(recorder.Body.String()).To(ContainSubstring(`"forbidden"`))
+	})
+	for _, role := range []string{"Admin", "Player"} {
+		role := role
+		It("should let the role "+role+", which takes part, call a participant route", func() {
+			logIn(role)
+			// the game has not been drawn, so it has no audit to show
+			mock.ExpectQuery("SELECT").WithArgs(code).WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(1, code, "Ready"))
+			Expect(call("GET", "/api/draw/audit")).To(Equal(http.StatusNotFound))
+			Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		})
+	}
+})
@@ -2,6 +2,7 @@ package service
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/go-playground/validator/v10"
 	log "github.com/sirupsen/logrus"
@@ -10,6 +11,7 @@ import (
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	logic "github.com/yoktobit/secretsanta/internal/gamemanagement/logic"
+	gerr "github.com/yoktobit/secretsanta/internal/gamemanagement/logic/errors"
 	to "github.com/yoktobit/secretsanta/internal/gamemanagement/logic/to"
 )
 
@@ -30,6 +32,7 @@ func NewRestService(gamemanagement logic.Gamemanagement) RestService {
 // DefineRoutes defines the routes
 func (restService *restService) DefineRoutes(r *gin.RouterGroup) {
 
+	r.Use(restService.authorize(r.BasePath()))
 	r.POST("/createNewGame", func(c *gin.Context) {
 		log.Infoln("createNewGame")
 		session := sessions.Default(c)
@@ -37,6 +40,13 @@ func (restService *restService) DefineRoutes(r *gin.RouterGroup) {
 		var createGameTo to.CreateGameTo
 		c.BindJSON(&createGameTo)
 		createGameResponseTo, err := restService.gamemanagement.CreateNewGame(createGameTo)
+		if previousGameFailed(c, err) {
+			return
+		}
+		if err == gerr.ErrEventDatePassed {
+			c.Status(http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			_, ok := err.(validator.ValidationErrors)
 			if ok {
@@ -44,6 +54,7 @@ func (restService *restService) DefineRoutes(r *gin.RouterGroup) {
 			} else {
 				c.Status(http.StatusInternalServerError)
 			}
+			return
 		}
 		log.Infoln("Spiel erstellt")
 		session.Clear()
@@ -58,31 +69,202 @@ func (restService *restService) DefineRoutes(r *gin.RouterGroup) {
 		var addPlayerTo to.AddRemovePlayerTo
 		c.BindJSON(&addPlayerTo)
 		addPlayerTo.GameCode = session.Get("gameCode").(string)
-		restService.gamemanagement.AddPlayerToGame(addPlayerTo)
-		c.Status(http.StatusOK)
+		inviteTo, err := restService.gamemanagement.AddPlayerToGame(addPlayerTo)
+		if isStateConflict(err) || err == gerr.ErrPlayerAlreadyExists {
+			c.Status(http.StatusConflict)
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, inviteTo)
 	})
 	r.POST("/removePlayer", func(c *gin.Context) {
 		session := sessions.Default(c)
 		var removePlayerTo to.AddRemovePlayerTo
 		c.BindJSON(&removePlayerTo)
 		removePlayerTo.GameCode = session.Get("gameCode").(string)
-		restService.gamemanagement.RemovePlayerFromGame(removePlayerTo)
+		err := restService.gamemanagement.RemovePlayerFromGame(removePlayerTo)
+		if isStateConflict(err) || err == gerr.ErrLastAdmin {
+			c.Status(http.StatusConflict)
+			return
+		}
 		c.Status(http.StatusOK)
 	})
+	r.POST("/dropOutPlayer", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		var dropOutPlayerTo to.DropOutPlayerTo
+		c.BindJSON(&dropOutPlayerTo)
+		dropOutPlayerTo.GameCode = gameCode.(string)
+		repairDrawResponseTo, err := restService.gamemanagement.RemovePlayerFromDrawnGame(dropOutPlayerTo)
+		if isStateConflict(err) || err == gerr.ErrLastAdmin {
+			c.Status(http.StatusConflict)
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, repairDrawResponseTo)
+	})
 
+	r.POST("/lateJoinPlayer", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		var lateJoinPlayerTo to.LateJoinPlayerTo
+		c.BindJSON(&lateJoinPlayerTo)
+		lateJoinPlayerTo.GameCode = gameCode.(string)
+		repairDrawResponseTo, err := restService.gamemanagement.AddPlayerToDrawnGame(lateJoinPlayerTo)
+		if isStateConflict(err) || err == gerr.ErrPlayerAlreadyExists {
+			c.Status(http.StatusConflict)
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, repairDrawResponseTo)
+	})
+
+	r.POST("/renamePlayer", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		var renamePlayerTo to.RenamePlayerTo
+		c.BindJSON(&renamePlayerTo)
+		renamePlayerTo.GameCode = gameCode.(string)
+		err := restService.gamemanagement.RenamePlayer(renamePlayerTo)
+		if err == gerr.ErrPlayerAlreadyExists {
+			c.Status(http.StatusConflict)
+			return
+		}
+		if err == gerr.ErrPlayerNotFound {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			_, ok := err.(validator.ValidationErrors)
+			if ok {
+				c.Status(http.StatusBadRequest)
+			} else {
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	r.POST("/promotePlayer", func(c *gin.Context) {
+		session := sessions.Default(c)
+		var changeRoleTo to.ChangeRoleTo
+		c.BindJSON(&changeRoleTo)
+		changeRoleTo.GameCode = session.Get("gameCode").(string)
+		err := restService.gamemanagement.PromotePlayer(changeRoleTo)
+		roleChanged(c, err)
+	})
+	r.POST("/demotePlayer", func(c *gin.Context) {
+		session := sessions.Default(c)
+		var changeRoleTo to.ChangeRoleTo
+		c.BindJSON(&changeRoleTo)
+		changeRoleTo.GameCode = session.Get("gameCode").(string)
+		err := restService.gamemanagement.DemotePlayer(changeRoleTo)
+		roleChanged(c, err)
+	})
+	r.POST("/transferAdmin", func(c *gin.Context) {
+		session := sessions.Default(c)
+		var transferAdminTo to.TransferAdminTo
+		c.BindJSON(&transferAdminTo)
+		transferAdminTo.GameCode = session.Get("gameCode").(string)
+		transferAdminTo.From = session.Get("player").(string)
+		err := restService.gamemanagement.TransferAdmin(transferAdminTo)
+		roleChanged(c, err)
+	})
 	r.POST("/registerPlayer", func(c *gin.Context) {
 		session := sessions.Default(c)
 		var registerPlayerPasswordTo to.RegisterLoginPlayerPasswordTo
 		c.BindJSON(&registerPlayerPasswordTo)
-		registerPlayerPasswordTo.GameCode = session.Get("gameCode").(string)
-		restService.gamemanagement.RegisterPlayerPassword(registerPlayerPasswordTo)
+		// the invite link carries the game code, so a player can register without visiting the game before
+		if gameCode := session.Get("gameCode"); gameCode != nil {
+			registerPlayerPasswordTo.GameCode = gameCode.(string)
+		}
+		err := restService.gamemanagement.RegisterPlayerPassword(registerPlayerPasswordTo)
+		if err == gerr.ErrInvalidInvite {
+			c.JSON(http.StatusForbidden, to.ErrorResponseTo{Error: "invalidInvite", Message: "Der Einladungslink ist ungültig oder abgelaufen."})
+			return
+		}
+		if isStateConflict(err) {
+			c.Status(http.StatusConflict)
+			return
+		}
+		if err == gorm.ErrRecordNotFound {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			_, ok := err.(validator.ValidationErrors)
+			if ok {
+				c.Status(http.StatusBadRequest)
+			} else {
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+		// the player logs in with the new password afterwards, the invite link alone does not open a session
+		c.Status(http.StatusOK)
+	})
+	r.POST("/invite", func(c *gin.Context) {
+		session := sessions.Default(c)
+		var invitePlayerTo to.InvitePlayerTo
+		c.BindJSON(&invitePlayerTo)
+		invitePlayerTo.GameCode = session.Get("gameCode").(string)
+		inviteTo, err := restService.gamemanagement.IssueInvite(invitePlayerTo)
+		if err == gerr.ErrPlayerNotFound {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		if err == gerr.ErrPlayerAlreadyRegistered {
+			c.JSON(http.StatusConflict, to.ErrorResponseTo{Error: "alreadyRegistered", Message: "Die Person hat sich schon registriert."})
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, inviteTo)
+	})
+	r.POST("/revokeInvite", func(c *gin.Context) {
+		session := sessions.Default(c)
+		var invitePlayerTo to.InvitePlayerTo
+		c.BindJSON(&invitePlayerTo)
+		invitePlayerTo.GameCode = session.Get("gameCode").(string)
+		err := restService.gamemanagement.RevokeInvite(invitePlayerTo)
+		if err == gerr.ErrPlayerNotFound {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
 		c.Status(http.StatusOK)
 	})
 	r.POST("/loginPlayer", func(c *gin.Context) {
 		session := sessions.Default(c)
 		var loginPlayerPasswordTo to.RegisterLoginPlayerPasswordTo
 		c.BindJSON(&loginPlayerPasswordTo)
-		loginPlayerResponseTo := restService.gamemanagement.LoginPlayer(loginPlayerPasswordTo)
+		loginPlayerResponseTo := restService.gamemanagement.LoginPlayer(loginPlayerPasswordTo, c.ClientIP())
 		if loginPlayerResponseTo.Ok {
 			log.Infoln("Alles ok")
 			log.Infoln(loginPlayerPasswordTo.GameCode)
@@ -92,6 +274,11 @@ func (restService *restService) DefineRoutes(r *gin.RouterGroup) {
 			session.Clear()
 		}
 		session.Save()
+		if loginPlayerResponseTo.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(loginPlayerResponseTo.RetryAfter))
+			c.JSON(http.StatusTooManyRequests, loginPlayerResponseTo)
+			return
+		}
 		c.JSON(http.StatusOK, loginPlayerResponseTo)
 	})
 	r.POST("/addException", func(c *gin.Context) {
@@ -104,9 +291,162 @@ func (restService *restService) DefineRoutes(r *gin.RouterGroup) {
 		var addExceptionTo to.AddExceptionTo
 		c.BindJSON(&addExceptionTo)
 		addExceptionTo.GameCode = gameCode.(string)
-		restService.gamemanagement.AddException(addExceptionTo)
+		feasibilityResponseTo, err := restService.gamemanagement.AddException(addExceptionTo)
+		if drawSearchGaveUp(c, err) {
+			return
+		}
+		if err == gerr.ErrDrawInfeasible {
+			c.JSON(http.StatusConflict, feasibilityResponseTo)
+			return
+		}
+		if isStateConflict(err) {
+			c.Status(http.StatusConflict)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	r.POST("/removeException", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		var removeExceptionTo to.RemoveExceptionTo
+		c.BindJSON(&removeExceptionTo)
+		removeExceptionTo.GameCode = gameCode.(string)
+		err := restService.gamemanagement.RemoveException(removeExceptionTo)
+		if isStateConflict(err) {
+			c.Status(http.StatusConflict)
+			return
+		}
+		if err == gorm.ErrRecordNotFound {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
 		c.Status(http.StatusOK)
 	})
+	r.PUT("/exceptions", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		var replaceExceptionsTo to.ReplaceExceptionsTo
+		c.BindJSON(&replaceExceptionsTo)
+		replaceExceptionsTo.GameCode = gameCode.(string)
+		feasibilityResponseTo, err := restService.gamemanagement.ReplaceExceptions(replaceExceptionsTo)
+		if drawSearchGaveUp(c, err) {
+			return
+		}
+		if err == gerr.ErrDrawInfeasible {
+			c.JSON(http.StatusConflict, feasibilityResponseTo)
+			return
+		}
+		if isStateConflict(err) {
+			c.Status(http.StatusConflict)
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.JSON(http.StatusOK, feasibilityResponseTo)
+	})
+	r.GET("/feasibility", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		feasibilityResponseTo, err := restService.gamemanagement.CheckFeasibility(gameCode.(string))
+		if drawSearchGaveUp(c, err) {
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, feasibilityResponseTo)
+	})
+	r.POST("/addHousehold", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		var addHouseholdTo to.AddRemoveHouseholdTo
+		c.BindJSON(&addHouseholdTo)
+		addHouseholdTo.GameCode = gameCode.(string)
+		err := restService.gamemanagement.AddHousehold(addHouseholdTo)
+		if isStateConflict(err) {
+			c.Status(http.StatusConflict)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	r.POST("/removeHousehold", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		var removeHouseholdTo to.AddRemoveHouseholdTo
+		c.BindJSON(&removeHouseholdTo)
+		removeHouseholdTo.GameCode = gameCode.(string)
+		err := restService.gamemanagement.RemoveHousehold(removeHouseholdTo)
+		if isStateConflict(err) {
+			c.Status(http.StatusConflict)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	r.POST("/assignHousehold", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		var assignHouseholdTo to.AssignHouseholdTo
+		c.BindJSON(&assignHouseholdTo)
+		assignHouseholdTo.GameCode = gameCode.(string)
+		feasibilityResponseTo, err := restService.gamemanagement.AssignPlayerToHousehold(assignHouseholdTo)
+		if drawSearchGaveUp(c, err) {
+			return
+		}
+		if err == gerr.ErrDrawInfeasible {
+			c.JSON(http.StatusConflict, feasibilityResponseTo)
+			return
+		}
+		if isStateConflict(err) {
+			c.Status(http.StatusConflict)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	r.GET("/households", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		householdResponseTos, err := restService.gamemanagement.GetHouseholdsByCode(gameCode.(string))
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, householdResponseTos)
+	})
 	r.GET("/draw", func(c *gin.Context) {
 		session := sessions.Default(c)
 		gameCode := session.Get("gameCode")
@@ -116,12 +456,68 @@ func (restService *restService) DefineRoutes(r *gin.RouterGroup) {
 		}
 		drawGameTo := to.DrawGameTo{GameCode: gameCode.(string)}
 		drawGameResponseTo, err := restService.gamemanagement.DrawGame(drawGameTo)
+		if drawSearchGaveUp(c, err) {
+			return
+		}
+		if isStateConflict(err) || err == gerr.ErrEventDatePassed {
+			c.Status(http.StatusConflict)
+			return
+		}
 		if err != nil {
 			c.Status(http.StatusInternalServerError)
 			return
 		}
 		c.JSON(http.StatusOK, drawGameResponseTo)
 	})
+	r.GET("/draw/preview", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		drawPreviewTo, err := restService.gamemanagement.PreviewDraw(gameCode.(string))
+		if drawSearchGaveUp(c, err) {
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, drawPreviewTo)
+	})
+	r.GET("/draw/audit", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		drawAuditTo, err := restService.gamemanagement.GetDrawAudit(gameCode.(string))
+		if err == gorm.ErrRecordNotFound {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, drawAuditTo)
+	})
+	r.POST("/draw/verify", func(c *gin.Context) {
+		var drawAuditTo to.DrawAuditTo
+		c.BindJSON(&drawAuditTo)
+		err := restService.gamemanagement.VerifyDraw(drawAuditTo)
+		if err == gerr.ErrDrawVerificationFailed {
+			c.Status(http.StatusConflict)
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
 	r.GET("/reset", func(c *gin.Context) {
 		session := sessions.Default(c)
 		gameCode := session.Get("gameCode")
@@ -129,7 +525,33 @@ func (restService *restService) DefineRoutes(r *gin.RouterGroup) {
 			c.Status(http.StatusForbidden)
 			return
 		}
-		restService.gamemanagement.ResetGame(gameCode.(string))
+		err := restService.gamemanagement.ResetGame(gameCode.(string))
+		if isStateConflict(err) {
+			c.Status(http.StatusConflict)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	r.GET("/restore", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		err := restService.gamemanagement.RestoreGame(gameCode.(string))
+		if isStateConflict(err) || err == gerr.ErrDrawRoundOutdated {
+			c.Status(http.StatusConflict)
+			return
+		}
+		if err == gorm.ErrRecordNotFound {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
 		c.Status(http.StatusOK)
 	})
 	r.GET("/game/:gameCode", func(c *gin.Context) {
@@ -169,6 +591,36 @@ func (restService *restService) DefineRoutes(r *gin.RouterGroup) {
 		}
 		c.JSON(http.StatusOK, gameResultTo)
 	})
+	r.PATCH("/game", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		var updateGameSettingsTo to.UpdateGameSettingsTo
+		c.BindJSON(&updateGameSettingsTo)
+		updateGameSettingsTo.GameCode = gameCode.(string)
+		updateGameSettingsTo.PlayerName = session.Get("player").(string)
+		err := restService.gamemanagement.UpdateGameSettings(updateGameSettingsTo)
+		if isStateConflict(err) || err == gerr.ErrEventDateMovedEarlier || err == gerr.ErrEventDatePassed {
+			c.Status(http.StatusConflict)
+			return
+		}
+		if previousGameFailed(c, err) {
+			return
+		}
+		if err != nil {
+			_, ok := err.(validator.ValidationErrors)
+			if ok {
+				c.Status(http.StatusBadRequest)
+			} else {
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+		c.Status(http.StatusOK)
+	})
 	r.GET("/players", func(c *gin.Context) {
 		session := sessions.Default(c)
 		gameCode := session.Get("gameCode")
@@ -197,6 +649,54 @@ func (restService *restService) DefineRoutes(r *gin.RouterGroup) {
 		}
 		c.JSON(http.StatusOK, exceptionResponseTos)
 	})
+	r.POST("/addPreference", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		var addPreferenceTo to.AddPreferenceTo
+		c.BindJSON(&addPreferenceTo)
+		addPreferenceTo.GameCode = gameCode.(string)
+		err := restService.gamemanagement.AddPreference(addPreferenceTo)
+		if isStateConflict(err) || err == gerr.ErrPlayerPreferenceAlreadyExists {
+			c.Status(http.StatusConflict)
+			return
+		}
+		preferenceChanged(c, err)
+	})
+	r.POST("/removePreference", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		var removePreferenceTo to.RemovePreferenceTo
+		c.BindJSON(&removePreferenceTo)
+		removePreferenceTo.GameCode = gameCode.(string)
+		err := restService.gamemanagement.RemovePreference(removePreferenceTo)
+		if isStateConflict(err) {
+			c.Status(http.StatusConflict)
+			return
+		}
+		preferenceChanged(c, err)
+	})
+	r.GET("/preferences", func(c *gin.Context) {
+		session := sessions.Default(c)
+		gameCode := session.Get("gameCode")
+		if gameCode == nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		preferenceResponseTos, err := restService.gamemanagement.GetPreferencesByCode(gameCode.(string))
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, preferenceResponseTos)
+	})
 	r.GET("/logout", func(c *gin.Context) {
 		session := sessions.Default(c)
 		session.Set("player", "") // this will mark the session as "written" and hopefully remove the username
@@ -229,3 +729,72 @@ func (restService *restService) DefineRoutes(r *gin.RouterGroup) {
 		c.JSON(http.StatusOK, result)
 	})
 }
+
+// isStateConflict tells whether an operation was refused because of the status of the game
+func isStateConflict(err error) bool {
+	return err == gerr.ErrGameAlreadyDrawn || err == gerr.ErrGameNotDrawn || err == gerr.ErrGameNotReady || err == gerr.ErrGameNotReset || err == gerr.ErrInvalidStatusTransition
+}
+
+// preferenceChanged answers a change of the soft preferences
+func preferenceChanged(c *gin.Context, err error) {
+
+	if _, ok := err.(validator.ValidationErrors); ok {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	switch err {
+	case nil:
+		c.Status(http.StatusOK)
+	case gorm.ErrRecordNotFound:
+		c.Status(http.StatusNotFound)
+	default:
+		c.Status(http.StatusInternalServerError)
+	}
+}
+
+// previousGameFailed answers the errors of linking the previous game, false if there is none of them
+func previousGameFailed(c *gin.Context, err error) bool {
+
+	switch err {
+	case gerr.ErrPreviousGameForbidden:
+		c.JSON(http.StatusForbidden, to.ErrorResponseTo{Error: "previousGameForbidden", Message: "Nur die Spielleitung des Vorjahres kann das Spiel verknüpfen."})
+	case gerr.ErrInvalidPreviousGame:
+		c.Status(http.StatusBadRequest)
+	case gorm.ErrRecordNotFound:
+		c.Status(http.StatusNotFound)
+	default:
+		return false
+	}
+	return true
+}
+
+// drawSearchGaveUp answers a draw or a check of the exceptions whose search for lots gave up, false if it did not
+func drawSearchGaveUp(c *gin.Context, err error) bool {
+
+	if err != gerr.ErrDrawSearchGaveUp {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, to.ErrorResponseTo{Error: "drawSearchGaveUp", Message: "Mit diesen Ausnahmen dauert die Suche nach einer Auslosung zu lange. Bitte einige Ausnahmen entfernen oder die Regeln der Auslosung lockern."})
+	return true
+}
+
+// roleChanged answers a change of the admin role
+func roleChanged(c *gin.Context, err error) {
+
+	switch {
+	case err == nil:
+		c.Status(http.StatusOK)
+	case err == gerr.ErrPlayerNotFound:
+		c.Status(http.StatusNotFound)
+	case err == gerr.ErrLastAdmin:
+		c.JSON(http.StatusConflict, to.ErrorResponseTo{Error: "lastAdmin", Message: "Das Spiel braucht mindestens eine Spielleitung."})
+	case err == gerr.ErrPlayerNotAdmin:
+		c.JSON(http.StatusForbidden, to.ErrorResponseTo{Error: "forbidden", Message: "Das darf nur die Spielleitung."})
+	default:
+		if _, ok := err.(validator.ValidationErrors); ok {
+			c.Status(http.StatusBadRequest)
+		} else {
+			c.Status(http.StatusInternalServerError)
+		}
+	}
+}
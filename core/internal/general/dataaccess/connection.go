@@ -23,7 +23,7 @@ type Config struct {
 // Connection encapsulates some DB connection
 type Connection interface {
 	Connection() *gorm.DB
-	NewTransaction(f func(Connection) error)
+	NewTransaction(f func(Connection) error) error
 }
 
 type connection struct {
@@ -60,9 +60,9 @@ func (c *connection) Connection() *gorm.DB {
 	return c.db
 }
 
-// NewTransaction defines a new Transaction
-func (c connection) NewTransaction(f func(Connection) error) {
-	c.Connection().Transaction(func(tx *gorm.DB) error {
+// NewTransaction defines a new Transaction, it is rolled back if f returns an error
+func (c connection) NewTransaction(f func(Connection) error) error {
+	return c.Connection().Transaction(func(tx *gorm.DB) error {
 		return f(&connection{db: tx})
 	})
 }
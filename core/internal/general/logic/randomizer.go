@@ -1,13 +1,38 @@
 package logic
 
 import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
 	"math/rand"
-	"time"
+	"os"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
 )
 
 // A Randomizer generated random numbers
 type Randomizer interface {
+	// NextInt returns a number in [0, upper)
 	NextInt(upper int) int
+	// NextIntBetween returns a number in [lower, upper]
+	NextIntBetween(lower int, upper int) int
+	// Shuffle puts size elements in random order by calling swap (Fisher-Yates)
+	Shuffle(size int, swap func(i int, j int))
+	// Perm returns the numbers 0..size-1 in random order
+	Perm(size int) []int
+	// NextSeed returns a seed for a seeded randomizer which can not be guessed in advance
+	NextSeed() int64
+}
+
+// RandomizerConfig is the configuration for the randomizer
+type RandomizerConfig struct {
+	// Kind is "crypto" (default) or "seeded"
+	Kind string
+	// Seed is used by the seeded randomizer
+	Seed int64
 }
 
 type randomizer struct {
@@ -18,11 +43,64 @@ type mockRandomizer struct {
 	generatingSelf bool
 }
 
-// NewRandomizer creates a real randomizer
+// cryptoSource is a math/rand source reading from crypto/rand, so that no seed can be guessed
+type cryptoSource struct{}
+
+// seededSource is a math/rand source deriving its numbers from all 64 bits of a seed with HMAC-SHA256 in counter mode.
+// The numbers can not be told apart from random ones without the seed, and the seed can not be found from them.
+type seededSource struct {
+	mac     hash.Hash
+	counter uint64
+	block   []byte
+}
+
+// NewRandomizer creates a real randomizer, the numbers come from crypto/rand
 func NewRandomizer() Randomizer {
-	rndSource := rand.NewSource(time.Now().UnixNano())
-	rnd := rand.New(rndSource)
-	return &randomizer{rand: rnd}
+	return NewCryptoRandomizer()
+}
+
+// NewCryptoRandomizer creates a randomizer whose numbers can not be predicted
+func NewCryptoRandomizer() Randomizer {
+	return &randomizer{rand: rand.New(cryptoSource{})}
+}
+
+// NewSeededRandomizer creates a randomizer which always generates the same numbers for the same seed
+func NewSeededRandomizer(seed int64) Randomizer {
+	return &randomizer{rand: rand.New(newSeededSource(seed))}
+}
+
+// NewLegacySeededRandomizer creates the seeded randomizer of math/rand, which only uses about 31 bits of the seed.
+// It is kept to verify draws made with it.
+func NewLegacySeededRandomizer(seed int64) Randomizer {
+	return &randomizer{rand: rand.New(rand.NewSource(seed))}
+}
+
+// NewRandomizerWithEnvironment creates the randomizer configured by the environment parameters RANDOMIZER and RANDOM_SEED
+func NewRandomizerWithEnvironment() Randomizer {
+
+	config := RandomizerConfig{Kind: os.Getenv("RANDOMIZER")}
+	if seed := os.Getenv("RANDOM_SEED"); seed != "" {
+		value, err := strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			panic("RANDOM_SEED must be a number!")
+		}
+		config.Seed = value
+	}
+	return NewRandomizerWithConfig(config)
+}
+
+// NewRandomizerWithConfig creates the randomizer of the given config
+func NewRandomizerWithConfig(config RandomizerConfig) Randomizer {
+
+	switch config.Kind {
+	case "", "crypto":
+		return NewCryptoRandomizer()
+	case "seeded":
+		log.Warn("Seeded randomizer in use, draws are predictable")
+		return NewSeededRandomizer(config.Seed)
+	default:
+		panic("Unknown randomizer " + config.Kind)
+	}
 }
 
 // NewMockRandomizer creates a new mocked randomizer
@@ -35,6 +113,24 @@ func (rnd *randomizer) NextInt(upper int) int {
 	return rnd.rand.Intn(upper)
 }
 
+func (rnd *randomizer) NextIntBetween(lower int, upper int) int {
+	return lower + rnd.NextInt(upper-lower+1)
+}
+
+func (rnd *randomizer) Shuffle(size int, swap func(i int, j int)) {
+	shuffle(rnd, size, swap)
+}
+
+func (rnd *randomizer) Perm(size int) []int {
+	return perm(rnd, size)
+}
+
+// NextSeed comes from the source of the randomizer, so only the crypto randomizer reads crypto/rand and a seeded one
+// always returns the same seeds for the same seed
+func (rnd *randomizer) NextSeed() int64 {
+	return rnd.rand.Int63()
+}
+
 func (rnd *mockRandomizer) NextInt(upper int) int {
 	if upper == 4 && rnd.generatingSelf {
 		rnd.generatingSelf = false
@@ -45,3 +141,82 @@ func (rnd *mockRandomizer) NextInt(upper int) int {
 	}
 	return upper - 1
 }
+
+func (rnd *mockRandomizer) NextIntBetween(lower int, upper int) int {
+	return lower + rnd.NextInt(upper-lower+1)
+}
+
+func (rnd *mockRandomizer) Shuffle(size int, swap func(i int, j int)) {
+	shuffle(rnd, size, swap)
+}
+
+func (rnd *mockRandomizer) Perm(size int) []int {
+	return perm(rnd, size)
+}
+
+func (rnd *mockRandomizer) NextSeed() int64 {
+	return 42
+}
+
+func (source cryptoSource) Int63() int64 {
+	return int64(source.Uint64() >> 1)
+}
+
+func (source cryptoSource) Uint64() uint64 {
+	var value [8]byte
+	if _, err := crand.Read(value[:]); err != nil {
+		panic("crypto/rand is not available: " + err.Error())
+	}
+	return binary.BigEndian.Uint64(value[:])
+}
+
+func (source cryptoSource) Seed(seed int64) {
+	// crypto/rand can not be seeded
+}
+
+func newSeededSource(seed int64) *seededSource {
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], uint64(seed))
+	return &seededSource{mac: hmac.New(sha256.New, key[:])}
+}
+
+func (source *seededSource) Int63() int64 {
+	return int64(source.Uint64() >> 1)
+}
+
+// Uint64 takes the next 8 bytes of the current block, the n-th block is the HMAC of the counter n
+func (source *seededSource) Uint64() uint64 {
+	if len(source.block) < 8 {
+		var counter [8]byte
+		binary.BigEndian.PutUint64(counter[:], source.counter)
+		source.counter++
+		source.mac.Reset()
+		source.mac.Write(counter[:])
+		source.block = source.mac.Sum(nil)
+	}
+	value := binary.BigEndian.Uint64(source.block[:8])
+	source.block = source.block[8:]
+	return value
+}
+
+func (source *seededSource) Seed(seed int64) {
+	*source = *newSeededSource(seed)
+}
+
+// shuffle is built on NextInt only, so a seeded randomizer always shuffles the same way
+func shuffle(rnd Randomizer, size int, swap func(i int, j int)) {
+	for i := size - 1; i > 0; i-- {
+		swap(i, rnd.NextInt(i+1))
+	}
+}
+
+func perm(rnd Randomizer, size int) []int {
+	indices := make([]int, size)
+	for i := range indices {
+		indices[i] = i
+	}
+	shuffle(rnd, size, func(i int, j int) {
+		indices[i], indices[j] = indices[j], indices[i]
+	})
+	return indices
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -14,6 +15,11 @@ import (
 func main() {
 	log.SetLevel(log.InfoLevel)
 	r := gin.Default()
+	// only trusted proxies may set the client IP, which the login throttling relies on, so none are trusted by default
+	r.TrustedProxies = []string{}
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		r.TrustedProxies = strings.Split(proxies, ",")
+	}
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{os.Getenv("ALLOWED_HOSTS")},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH"},
@@ -13,6 +13,6 @@ import (
 
 // InitializeEvent wires together the dependencies
 func InitializeEvent() service.RestService {
-	wire.Build(service.NewRestService, logic.NewGamemanagement, dataaccess.NewPlayerExceptionRepository, dataaccess.NewPlayerRepository, dataaccess.NewGameRepository, dataaccess_general.NewConnectionWithEnvironment, logic_general.NewRandomizer)
+	wire.Build(service.NewRestService, logic.NewGamemanagement, logic.NewDefaultDrawStrategies, dataaccess.NewPlayerExceptionRepository, dataaccess.NewHouseholdRepository, dataaccess.NewAssignmentRepository, dataaccess.NewPlayerPreferenceRepository, dataaccess.NewDrawAuditRepository, dataaccess.NewDrawRoundRepository, dataaccess.NewInviteRepository, dataaccess.NewLoginAuditRepository, dataaccess.NewLoginAttemptRepository, logic.NewLoginAttemptStoreWithEnvironment, dataaccess.NewPlayerRepository, dataaccess.NewGameRepository, dataaccess_general.NewConnectionWithEnvironment, logic_general.NewRandomizerWithEnvironment)
 	return service.NewRestService(nil)
 }
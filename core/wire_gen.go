@@ -21,8 +21,18 @@ func InitializeEvent() service.RestService {
 	gameRepository := dataaccess2.NewGameRepository(connection)
 	playerRepository := dataaccess2.NewPlayerRepository(connection)
 	playerExceptionRepository := dataaccess2.NewPlayerExceptionRepository(connection)
-	randomizer := logic.NewRandomizer()
-	gamemanagement := logic2.NewGamemanagement(connection, gameRepository, playerRepository, playerExceptionRepository, randomizer)
+	householdRepository := dataaccess2.NewHouseholdRepository(connection)
+	assignmentRepository := dataaccess2.NewAssignmentRepository(connection)
+	playerPreferenceRepository := dataaccess2.NewPlayerPreferenceRepository(connection)
+	drawAuditRepository := dataaccess2.NewDrawAuditRepository(connection)
+	drawRoundRepository := dataaccess2.NewDrawRoundRepository(connection)
+	inviteRepository := dataaccess2.NewInviteRepository(connection)
+	loginAuditRepository := dataaccess2.NewLoginAuditRepository(connection)
+	loginAttemptRepository := dataaccess2.NewLoginAttemptRepository(connection)
+	loginAttemptStore := logic2.NewLoginAttemptStoreWithEnvironment(loginAttemptRepository)
+	drawStrategies := logic2.NewDefaultDrawStrategies()
+	randomizer := logic.NewRandomizerWithEnvironment()
+	gamemanagement := logic2.NewGamemanagement(connection, gameRepository, playerRepository, playerExceptionRepository, householdRepository, assignmentRepository, playerPreferenceRepository, drawAuditRepository, drawRoundRepository, inviteRepository, loginAuditRepository, loginAttemptStore, drawStrategies, randomizer)
 	restService := service.NewRestService(gamemanagement)
 	return restService
 }
@@ -5,7 +5,7 @@ class Game {
   final String name;
   final String description;
   final Status status;
-  final String gifted;
+  final List<String> gifted;
 
   Game(this.code, this.name, this.description, this.status, this.gifted);
 
@@ -15,7 +15,7 @@ class Game {
       json['name'],
       json['description'],
       json['status'],
-      json['gifted'],
+      List<String>.from(json['gifted'] ?? []),
     );
   }
 
@@ -6,12 +6,14 @@ import { LoggedInGuard } from './logged-in.guard';
 import { LoginComponent } from './login/login.component';
 import { ManagePlayersComponent } from './manage-players/manage-players.component';
 import { NewGameComponent } from './new-game/new-game.component';
+import { RegisterComponent } from './register/register.component';
 import { WelcomeComponent } from './welcome/welcome.component';
 
 const routes: Routes = [
   {path: "", component: WelcomeComponent},
   {path: "newGame", component: NewGameComponent},
   {path: "login", component: LoginComponent},
+  {path: "register/:gameCode/:token", component: RegisterComponent},
   {path: "gameCreated", component: GameCreatedComponent},
   {path: "players", component: ManagePlayersComponent, canActivate: [LoggedInGuard]},
   {path: "game/:gameCode", component: GameComponent},
@@ -28,6 +28,7 @@ import { LoginComponent } from './login/login.component';
 import { ManagePlayersComponent } from './manage-players/manage-players.component';
 import { MenuComponent } from './menu/menu.component';
 import { NewGameComponent } from './new-game/new-game.component';
+import { RegisterComponent } from './register/register.component';
 import { WelcomeComponent } from './welcome/welcome.component';
 import { ServiceWorkerModule } from '@angular/service-worker';
 import { environment } from '../environments/environment';
@@ -42,7 +43,8 @@ import { environment } from '../environments/environment';
     GameCreatedComponent,
     ManagePlayersComponent,
     GameComponent,
-    ConfirmResetDialogComponent
+    ConfirmResetDialogComponent,
+    RegisterComponent
   ],
   imports: [
     BrowserModule,
@@ -8,9 +8,11 @@ import { CreateGameResponseTo } from './shared/models/create-game-response-to.mo
 import { CreateGameTo } from './shared/models/create-game-to.model';
 import { DrawGameResultTo } from './shared/models/draw-game-result-to.model';
 import { Exception } from './shared/models/exception.model';
+import { InviteTo } from './shared/models/invite-to.model';
 import { LoginResponseTo } from './shared/models/login-response-to.model';
 import { LoginTo } from './shared/models/login-to.model';
 import { Player } from './shared/models/player.model';
+import { RegisterTo } from './shared/models/register-to.model';
 import { StatusResultTo } from './shared/models/status-result-to.model';
 
 @Injectable({
@@ -48,8 +50,16 @@ export class BackendService {
     return this.http.get<StatusResultTo>(this.url + "api/status", this.defaultOptions)
   }
 
-  addPlayer(addPlayerTo: AddRemovePlayerTo): Observable<void> {
-    return this.http.post<void>(this.url + "api/addPlayer", addPlayerTo, this.defaultOptions);
+  addPlayer(addPlayerTo: AddRemovePlayerTo): Observable<InviteTo> {
+    return this.http.post<InviteTo>(this.url + "api/addPlayer", addPlayerTo, this.defaultOptions);
+  }
+
+  invite(invitePlayerTo: AddRemovePlayerTo): Observable<InviteTo> {
+    return this.http.post<InviteTo>(this.url + "api/invite", invitePlayerTo, this.defaultOptions);
+  }
+
+  revokeInvite(invitePlayerTo: AddRemovePlayerTo): Observable<void> {
+    return this.http.post<void>(this.url + "api/revokeInvite", invitePlayerTo, this.defaultOptions);
   }
 
   removePlayer(removePlayerTo: AddRemovePlayerTo): Observable<void> {
@@ -68,6 +78,10 @@ export class BackendService {
     return this.http.get(this.url + "api/reset", this.defaultOptions);
   }
 
+  register(registerTo: RegisterTo): Observable<void> {
+    return this.http.post<void>(this.url + "api/registerPlayer", registerTo, this.defaultOptions);
+  }
+
   login(loginTo: LoginTo): Observable<LoginResponseTo> {
     return this.http.post<LoginResponseTo>(this.url + "api/loginPlayer", loginTo, this.defaultOptions);
   }
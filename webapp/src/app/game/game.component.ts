@@ -66,6 +66,11 @@ export class GameComponent implements OnInit {
     this.router.navigate([""]);
   }
 
+  // admins and organizers manage the game, organizers do not take part in the draw
+  managesGame(): boolean {
+    return this.status?.role == "Admin" || this.status?.role == "Organizer";
+  }
+
   login() {
     this.router.navigate(["/login"], {state: { "gameCode": this.linkedGame.code}})
   }
@@ -7,6 +7,7 @@ import { AddRemovePlayerTo } from '../shared/models/add-remove-player-to.model';
 import { BackendService } from './../backend.service';
 import { AddExceptionTo } from './../shared/models/add-exception-to.model';
 import { Exception } from './../shared/models/exception.model';
+import { InviteTo } from './../shared/models/invite-to.model';
 import { Player } from './../shared/models/player.model';
 import { StatusResultTo } from './../shared/models/status-result-to.model';
 import { StatusService } from './../status.service';
@@ -23,6 +24,7 @@ export class ManagePlayersComponent implements OnInit {
   created: boolean = false;
   players: Player[] = [];
   exceptions: Exception[] = [];
+  invites: InviteTo[] = [];
   displayedColumnsPlayerList: string[] = ["select", "name", "status"]
   displayedColumns: string[] = ['nameA', 'direction', 'nameB'];
   selection = new SelectionModel<Player>(true, []);
@@ -57,15 +59,48 @@ export class ManagePlayersComponent implements OnInit {
     return this.baseUrl() + "/game/" + this.game?.code;
   }
 
+  // every player registers with an own invite link, it can only be used once
+  inviteUrl(invite: InviteTo): string {
+
+    return this.baseUrl() + "/register/" + this.game?.code + "/" + invite.token;
+  }
+
   onSubmit(f: NgForm) {
 
     let addPlayerTo = new AddRemovePlayerTo(f.value.name);
-    this.backend.addPlayer(addPlayerTo).subscribe(() => {
+    this.backend.addPlayer(addPlayerTo).subscribe(invite => {
+      this.addInvite(invite);
       this.refreshGame();
       f.resetForm();
     })
   }
 
+  invitePlayer() {
+
+    this.selection.selected.forEach((element) => {
+      let invitePlayerTo = new AddRemovePlayerTo(element.name);
+      this.backend.invite(invitePlayerTo).subscribe(invite => {
+        this.addInvite(invite);
+      })
+    });
+  }
+
+  revokeInvite() {
+
+    this.selection.selected.forEach((element) => {
+      let invitePlayerTo = new AddRemovePlayerTo(element.name);
+      this.backend.revokeInvite(invitePlayerTo).subscribe(() => {
+        this.invites = this.invites.filter(invite => invite.name != element.name);
+      })
+    });
+  }
+
+  // a new invite replaces the former one of the player, which is no longer valid
+  addInvite(invite: InviteTo) {
+
+    this.invites = this.invites.filter(other => other.name != invite.name).concat(invite);
+  }
+
   deletePlayer() {
     
     this.selection.selected.forEach((element) => {
@@ -18,4 +18,9 @@ export class MenuComponent implements OnInit {
       this.status = status;
     })
   }
+
+  // admins and organizers manage the game, organizers do not take part in the draw
+  managesGame(): boolean {
+    return this.status?.role == "Admin" || this.status?.role == "Organizer";
+  }
 }
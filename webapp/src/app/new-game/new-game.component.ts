@@ -20,13 +20,14 @@ export class NewGameComponent implements OnInit {
       title: ['', Validators.required],
       description: ['', Validators.required],
       adminUser: ['', Validators.required],
-      adminPassword: ['', Validators.required]
+      adminPassword: ['', Validators.required],
+      organizerOnly: [false]
     });
   }
 
   onSubmit(): void {
 
-    let createGameTo = new CreateGameTo(this.newGameForm.value.title, this.newGameForm.value.description, this.newGameForm.value.adminUser, this.newGameForm.value.adminPassword)
+    let createGameTo = new CreateGameTo(this.newGameForm.value.title, this.newGameForm.value.description, this.newGameForm.value.adminUser, this.newGameForm.value.adminPassword, this.newGameForm.value.organizerOnly)
     this.backend.createGame(createGameTo).subscribe(createGameResponseTo => {
       this.router.navigate(['/players'], {state: createGameResponseTo});
     }, (error) => {
@@ -0,0 +1,54 @@
+import { Component, OnInit } from '@angular/core';
+import { FormBuilder, FormGroup, Validators } from '@angular/forms';
+import { ActivatedRoute, Router } from '@angular/router';
+import { BackendService } from './../backend.service';
+import { LoginTo } from './../shared/models/login-to.model';
+import { RegisterTo } from './../shared/models/register-to.model';
+import { StatusService } from './../status.service';
+
+@Component({
+  selector: 'app-register',
+  templateUrl: './register.component.html',
+  styleUrls: ['./register.component.css']
+})
+export class RegisterComponent implements OnInit {
+
+  gameCode: string;
+
+  inviteToken: string;
+
+  registerForm: FormGroup;
+
+  message: string;
+
+  constructor(private route: ActivatedRoute, private router: Router, private formBuilder: FormBuilder, private backend: BackendService, private statusService: StatusService) {
+    this.gameCode = this.route.snapshot.paramMap.get("gameCode") ?? "";
+    this.inviteToken = this.route.snapshot.paramMap.get("token") ?? "";
+  }
+
+  ngOnInit(): void {
+    this.registerForm = this.formBuilder.group({
+      username: ['', Validators.required],
+      password: ['', Validators.required]
+    })
+  }
+
+  // the invite link only registers the password, the player is logged in with it afterwards
+  onSubmit(): void {
+    const registerTo = new RegisterTo(this.gameCode, this.registerForm.value.username, this.registerForm.value.password, this.inviteToken);
+    this.backend.register(registerTo).subscribe(() => {
+      const loginTo = new LoginTo(this.gameCode, this.registerForm.value.username, this.registerForm.value.password);
+      this.backend.login(loginTo).subscribe(result => {
+        if (result.ok) {
+          this.router.navigate(["/game"]);
+          this.message = "";
+        } else {
+          this.message = result.message;
+        }
+        this.statusService.refreshStatus();
+      })
+    }, (error) => {
+      this.message = error.error?.message ?? $localize `:@@registerFailed:Die Registrierung ist fehlgeschlagen.`;
+    });
+  }
+}
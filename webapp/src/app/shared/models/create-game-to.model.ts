@@ -3,12 +3,14 @@ export class CreateGameTo {
     description: string
     adminUser: string
     adminPassword: string
+    organizerOnly: boolean
 
-    constructor(title: string, description: string, adminUser: string, adminPassword: string) {
+    constructor(title: string, description: string, adminUser: string, adminPassword: string, organizerOnly: boolean) {
 
         this.title = title;
         this.description = description;
         this.adminUser = adminUser;
         this.adminPassword = adminPassword;
+        this.organizerOnly = organizerOnly;
     }
 }
\ No newline at end of file
@@ -1,5 +1,5 @@
 export class Game {
 
-    constructor(public name: string, public description: string, public status: string, public gifted: string) {
+    constructor(public name: string, public description: string, public status: string, public gifted: string[]) {
     }
 }
\ No newline at end of file
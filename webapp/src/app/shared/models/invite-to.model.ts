@@ -0,0 +1,4 @@
+export class InviteTo {
+
+    constructor(public name: string, public token: string, public expiresAt: string) {}
+}
\ No newline at end of file
@@ -0,0 +1,14 @@
+export class RegisterTo {
+
+    private gameCode: string;
+    private username: string;
+    private password: string;
+    private inviteToken: string;
+
+    constructor(gameCode: string, username: string, password: string, inviteToken: string) {
+        this.gameCode = gameCode;
+        this.username = username;
+        this.password = password;
+        this.inviteToken = inviteToken;
+    }
+}
\ No newline at end of file